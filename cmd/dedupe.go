@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/actions"
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/parameters"
+	"github.com/rs/zerolog"
+)
+
+// dedupe removes the duplicates of the groups and returns false if some of the files failed
+func dedupe(logger zerolog.Logger, params *parameters.RunParameters, groups []*checkers.FilesCheckGroup) bool {
+	if !params.Verify {
+		logger.Warn().Msg("Dedupe relies on hashes only, use -verify to compare files byte-by-byte first")
+	}
+
+	keeper, err := actions.NewKeeper(params.KeepPolicy, params.Paths, params.KeepRegex)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Cannot create keep policy")
+	}
+
+	if params.Script != "" {
+		return writeScript(logger, params, keeper, groups)
+	}
+
+	action, err := actions.NewAction(params.DedupeMode, actions.ActionOptions{
+		LinkStyle:     params.LinkStyle,
+		QuarantineDir: params.Quarantine,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Cannot create dedupe action")
+	}
+
+	// The changes are always recorded, so they can be undone even if no journal is set
+	journalPath := params.Journal
+	if journalPath == "" && !params.DryRun {
+		journalPath = actions.DefaultJournalPath(time.Now())
+		logger.Info().Msgf("Dedupe changes are recorded in the journal: %s", journalPath)
+	}
+
+	var journal *actions.Journal
+	if journalPath != "" {
+		journal, err = actions.OpenJournal(journalPath)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Cannot open journal")
+		}
+		defer journal.Close()
+	}
+
+	deduplicator := actions.NewDeduplicator(logger, keeper, action, journal, params.DryRun)
+
+	var stats actions.DedupeStats
+	if params.Interactive {
+		reviewer := actions.NewReviewer(os.Stdin, os.Stderr, keeper)
+		decisions, err := reviewer.Review(groups)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Cannot review duplicated groups")
+		}
+
+		confirmed, err := reviewer.Confirm(decisions)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Cannot review duplicated groups")
+		}
+
+		if !confirmed {
+			logger.Info().Msg("Dedupe cancelled, no files are changed")
+			return true
+		}
+
+		stats = deduplicator.DedupeDecisions(decisions)
+		stats.SkippedGroups += len(groups) - len(decisions)
+		reviewer.PrintSummary(stats)
+	} else {
+		stats = deduplicator.Dedupe(groups)
+	}
+
+	logger.Info().Msgf(
+		"Dedupe completed (mode: %s, dry run: %t): groups: %d, skipped groups: %d, files: %d, failed files: %d, reclaimed bytes: %d",
+		params.DedupeMode,
+		params.DryRun,
+		stats.Groups,
+		stats.SkippedGroups,
+		stats.Files,
+		stats.FailedFiles,
+		stats.ReclaimedBytes,
+	)
+
+	return stats.FailedFiles == 0
+}
+
+// writeScript writes the dedupe commands to the shell script for the review instead of running them.
+// It returns false if the commands of some files cannot be written.
+func writeScript(logger zerolog.Logger, params *parameters.RunParameters, keeper *actions.Keeper, groups []*checkers.FilesCheckGroup) bool {
+	scriptFile, err := os.Create(params.Script)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Cannot create script")
+	}
+	defer scriptFile.Close()
+
+	scriptWriter, err := actions.NewScriptWriter(scriptFile, keeper, params.DedupeMode, actions.ActionOptions{
+		LinkStyle: params.LinkStyle,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Cannot create script writer")
+	}
+
+	stats, err := scriptWriter.Write(groups)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Cannot write script")
+	}
+
+	logger.Info().Msgf(
+		"Dedupe script written to %s (mode: %s): groups: %d, skipped groups: %d, files: %d, failed files: %d, reclaimable bytes: %d",
+		params.Script,
+		params.DedupeMode,
+		stats.Groups,
+		stats.SkippedGroups,
+		stats.Files,
+		stats.FailedFiles,
+		stats.ReclaimedBytes,
+	)
+
+	return stats.FailedFiles == 0
+}
+
+// undo reverses the changes from the journal and returns false if some of them failed
+func undo(logger zerolog.Logger, journalPath string) bool {
+	entries, err := actions.ReadJournal(journalPath)
+	if err != nil {
+		logger.Error().Err(err).Msg("Cannot read journal")
+		return false
+	}
+
+	logger.Info().Msgf("Undoing %d changes from the journal: %s", len(entries), journalPath)
+	stats := actions.NewUndoer(logger).Undo(entries)
+	logger.Info().Msgf(
+		"Undo completed: restored: %d, not undoable: %d, failed: %d",
+		stats.Restored,
+		stats.NotUndoable,
+		stats.Failed,
+	)
+
+	return stats.Failed == 0
+}
@@ -1,90 +1,102 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/pryazhnikov/gofileschecker/internal/checkers"
 	"github.com/pryazhnikov/gofileschecker/internal/parameters"
-	"github.com/pryazhnikov/gofileschecker/internal/scanner"
 	"github.com/rs/zerolog"
 )
 
-func newLogger(debug bool) zerolog.Logger {
+// newLogger creates the logger writing to stderr or to the log file, stdout is reserved for the results.
+// The quiet logger writes the errors only, so the reason of the failed run is still shown.
+// The returned function closes the log file.
+func newLogger(params *parameters.RunParameters) (zerolog.Logger, func(), error) {
+	outFile := os.Stderr
+	closeFunc := func() {}
+	if params.LogFile != "" {
+		logFile, err := os.OpenFile(params.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return zerolog.Nop(), nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+
+		outFile = logFile
+		closeFunc = func() { logFile.Close() }
+	}
+
+	var out io.Writer = outFile
+	if params.LogFormat == parameters.LogFormatConsole {
+		out = zerolog.ConsoleWriter{Out: out, NoColor: !isTerminal(outFile), TimeFormat: time.DateTime}
+	}
+
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
-	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	logger := zerolog.New(out).With().Timestamp().Logger()
 
 	level := zerolog.InfoLevel
-	if debug {
+	switch {
+	case params.Quiet:
+		level = zerolog.ErrorLevel
+	case params.Debug:
 		level = zerolog.DebugLevel
 	}
 
-	return logger.Level(level)
+	return logger.Level(level), closeFunc, nil
+}
+
+// isTerminal checks if the file is a terminal, the colored output is not readable in the other files
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
 func main() {
 	paramsParser := parameters.NewRunParametersParser()
 	params, err := paramsParser.Parse(os.Args)
+	if errors.Is(err, flag.ErrHelp) {
+		paramsParser.Usage()
+		return
+	}
+
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
 		paramsParser.Usage()
 		os.Exit(1)
 	}
 
-	logger := newLogger(params.Debug)
-	logger.Info().Msgf("Logger level set: %s", logger.GetLevel().String())
-
-	fileChecker := checkers.NewFileChecker(params.SkipEmptyFiles)
-	scanner := scanner.NewDirectoryScanner(logger, fileChecker)
-
-	// Scanning all directories
-	for _, path := range params.Paths {
-		logger.Info().Msgf("Path to process: %s", path)
-		err = scanner.Scan(path)
-		if err != nil {
-			logger.Fatal().Err(err).Msgf("Cannot scan directory: %s", path)
-		}
-	}
-
-	scanRes := scanner.Summary()
-	logger.Info().Msgf(
-		"Directories: %d, files: %d, errors: %d, skipped: %d",
-		scanRes.Directories(),
-		scanRes.Files(),
-		scanRes.Errors(),
-		scanRes.Skipped(),
-	)
-
-	// Results combining
-	logger.Info().Msg("Directory scan completed, getting the results...")
-
-	fcg := fileChecker.GetDuplicatedFileGroups()
-	if len(fcg) == 0 {
-		logger.Info().Msg("No duplicated files found")
-		return
+	logger, closeLogger, err := newLogger(params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
+	defer closeLogger()
 
-	fmt.Printf("Found %d duplicated files groups\n", len(fcg))
-	for _, fcg := range fcg {
-		fmt.Printf(
-			"Duplicated files group: %s\n",
-			fcg.Hash(),
-		)
-
-		pathPrefix := fcg.CommonPathPrefix()
-		fmt.Printf("Location: %s\n", pathPrefix)
-
-		for _, file := range fcg.Files() {
-			fileView := file
-			if !params.FullFilePath {
-				fileView = strings.TrimPrefix(file, pathPrefix)
-			}
+	logger.Info().Msgf("Logger level set: %s", logger.GetLevel().String())
 
-			fmt.Printf("- %s\n", fileView)
+	ok := true
+	switch params.Command {
+	case parameters.CommandScan:
+		ok = runScan(logger, params)
+	case parameters.CommandReport, parameters.CommandVerify, parameters.CommandCompare:
+		_, ok = runReport(logger, params)
+	case parameters.CommandDedupe:
+		var groups []*checkers.FilesCheckGroup
+		if groups, ok = runReport(logger, params); ok {
+			ok = dedupe(logger, params, groups)
 		}
+	case parameters.CommandServe:
+		ok = runServe(logger, params)
+	case parameters.CommandUndo:
+		ok = undo(logger, params.Undo)
+	}
 
-		fmt.Println()
+	if !ok {
+		closeLogger()
+		os.Exit(1)
 	}
 
 	logger.Info().Msg("Done")
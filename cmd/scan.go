@@ -0,0 +1,277 @@
+package main
+
+import (
+	"cmp"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/parameters"
+	"github.com/pryazhnikov/gofileschecker/internal/report"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
+	"github.com/rs/zerolog"
+)
+
+func newScanInfo(params *parameters.RunParameters) report.ScanInfo {
+	return report.ScanInfo{
+		StartedAt:     time.Now(),
+		Paths:         params.Paths,
+		HashAlgorithm: params.HashAlgorithm,
+		Verify:        params.Verify,
+	}
+}
+
+// groupCollector verifies and filters every duplicated group as soon as the checker completes it
+type groupCollector struct {
+	verify  bool
+	roots   []string // The compared roots, the groups are not filtered if empty
+	onGroup func(group *checkers.FilesCheckGroup)
+	groups  []*checkers.FilesCheckGroup
+	issues  []checkers.VerificationIssue
+	err     error
+	mu      sync.Mutex
+}
+
+func (c *groupCollector) add(group *checkers.FilesCheckGroup) {
+	groups := []*checkers.FilesCheckGroup{group}
+	var issues []checkers.VerificationIssue
+	if c.verify {
+		groups, issues = checkers.VerifyGroups(groups, 1)
+	}
+
+	var err error
+	if len(c.roots) > 0 {
+		groups, err = checkers.CrossRootGroups(groups, c.roots)
+	}
+
+	c.mu.Lock()
+	c.groups = append(c.groups, groups...)
+	c.issues = append(c.issues, issues...)
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+
+	if c.onGroup != nil && err == nil {
+		for _, verified := range groups {
+			c.onGroup(verified)
+		}
+	}
+}
+
+// sorted returns the collected groups and issues in the stable order, the groups are completed in parallel
+func (c *groupCollector) sorted() ([]*checkers.FilesCheckGroup, []checkers.VerificationIssue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	slices.SortFunc(c.groups, func(a, b *checkers.FilesCheckGroup) int {
+		return cmp.Or(
+			strings.Compare(a.Hash(), b.Hash()),
+			cmp.Compare(a.Size(), b.Size()),
+			strings.Compare(a.Files()[0], b.Files()[0]),
+		)
+	})
+	slices.SortFunc(c.issues, func(a, b checkers.VerificationIssue) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return c.groups, c.issues
+}
+
+// scanPaths finds the duplicated groups, onGroup (if set) gets every group as soon as it is found.
+// If the scan is stopped by an error, no groups are returned and the error is set in the summary.
+func scanPaths(
+	logger zerolog.Logger,
+	params *parameters.RunParameters,
+	onProgress scanner.ProgressFunc,
+	onGroup func(group *checkers.FilesCheckGroup),
+) ([]*checkers.FilesCheckGroup, report.Summary) {
+	filter, err := scanner.NewPathFilter(params.Include, params.Exclude)
+	if err != nil {
+		logger.Error().Err(err).Msg("Cannot create path filter")
+		return nil, report.Summary{FinishedAt: time.Now(), Err: err}
+	}
+
+	collector := &groupCollector{verify: params.Verify, onGroup: onGroup}
+	if params.Command == parameters.CommandCompare {
+		collector.roots = params.Paths
+	}
+
+	fileChecker, err := checkers.NewFileChecker(checkers.Options{
+		PartialBlockSize: int64(params.PartialKB) * 1024,
+		HashAlgorithm:    params.HashAlgorithm,
+		OnGroup:          collector.add,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Cannot create file checker")
+		return nil, report.Summary{FinishedAt: time.Now(), Err: err}
+	}
+
+	var ignoreFiles []string
+	if params.IgnoreFiles {
+		ignoreFiles = scanner.DefaultIgnoreFiles
+	}
+
+	dirScanner := scanner.NewDirectoryScanner(logger, fileChecker, scanner.Options{
+		Workers:     params.Workers,
+		ErrorPolicy: params.ErrorPolicy,
+		MaxErrors:   params.MaxErrors,
+		OnProgress:  onProgress,
+		Filter:      filter,
+		IgnoreFiles: ignoreFiles,
+		MinSize:     params.MinSize,
+		MaxSize:     params.MaxSize,
+		NewerThan:   params.NewerThan,
+		OlderThan:   params.OlderThan,
+		Symlinks:    params.Symlinks,
+	})
+
+	// newSummary describes the current scan state
+	newSummary := func(issues []checkers.VerificationIssue, scanErr error) report.Summary {
+		return report.Summary{
+			FinishedAt:         time.Now(),
+			Stats:              dirScanner.Summary(),
+			CheckStats:         fileChecker.Stats(),
+			Failures:           dirScanner.Failures(),
+			VerificationIssues: issues,
+			Symlinks:           dirScanner.Symlinks(),
+			Err:                scanErr,
+		}
+	}
+
+	if params.Verify {
+		logger.Info().Msg("Duplicated files groups are verified byte-by-byte as soon as they are found")
+	}
+
+	// Scanning all directories
+	for _, path := range params.Paths {
+		logger.Info().Msgf("Path to process: %s", path)
+		if err := dirScanner.Scan(path); err != nil {
+			logger.Error().Err(err).Msgf("Cannot scan directory: %s", path)
+			return nil, newSummary(nil, err)
+		}
+	}
+
+	// Checking files contents
+	if err := dirScanner.CheckFiles(); err != nil {
+		logger.Error().Err(err).Msg("Cannot check files")
+		return nil, newSummary(nil, err)
+	}
+
+	checkStats := fileChecker.Stats()
+	logger.Info().Msgf(
+		"Registered files: %d, unique size files: %d, partially hashed files: %d (%d bytes), "+
+			"hashed files: %d (%d bytes), saved bytes: %d",
+		checkStats.RegisteredFiles,
+		checkStats.UniqueSizeFiles,
+		checkStats.PartialHashedFiles,
+		checkStats.PartialHashedBytes,
+		checkStats.HashedFiles,
+		checkStats.HashedBytes,
+		checkStats.SavedBytes,
+	)
+
+	scanRes := dirScanner.Summary()
+	logger.Info().Msgf(
+		"Directories: %d, files: %d, errors: %d, skipped: %d",
+		scanRes.Directories(),
+		scanRes.Files(),
+		scanRes.Errors(),
+		scanRes.Skipped(),
+	)
+
+	// Results combining
+	logger.Info().Msg("Directory scan completed, getting the results...")
+
+	fcg, issues := collector.sorted()
+	if collector.err != nil {
+		logger.Error().Err(collector.err).Msg("Cannot compare paths")
+		return nil, newSummary(issues, collector.err)
+	}
+
+	if len(fcg) == 0 {
+		logger.Info().Msg("No duplicated files found")
+	}
+
+	return fcg, newSummary(issues, nil)
+}
+
+// runScan writes the overview of the duplicates
+func runScan(logger zerolog.Logger, params *parameters.RunParameters) bool {
+	groups, summary := scanPaths(logger, params, nil, nil)
+	if err := report.WriteOverview(os.Stdout, summary, groups); err != nil {
+		logger.Error().Err(err).Msg("Cannot write overview")
+		return false
+	}
+
+	return summary.Err == nil
+}
+
+// runReport writes the report of the duplicated groups, the progress is written while scanning
+func runReport(logger zerolog.Logger, params *parameters.RunParameters) ([]*checkers.FilesCheckGroup, bool) {
+	reportWriter, err := report.NewWriter(params.Format, os.Stdout, report.WriterOptions{
+		FullFilePath: params.FullFilePath,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Cannot create report writer")
+	}
+
+	if err := reportWriter.Start(newScanInfo(params)); err != nil {
+		logger.Fatal().Err(err).Msg("Cannot write report")
+	}
+
+	// The streaming formats get the groups as soon as they are found, the others get them sorted at the end
+	var writeErr error
+	var writeErrOnce sync.Once
+	writeGroup := func(group *checkers.FilesCheckGroup) {
+		if err := reportWriter.WriteGroup(group); err != nil {
+			writeErrOnce.Do(func() { writeErr = err })
+		}
+	}
+
+	var onGroup func(group *checkers.FilesCheckGroup)
+	if params.Format.IsStreaming() {
+		onGroup = writeGroup
+	}
+
+	groups, summary := scanPaths(logger, params, func(progress scanner.Progress) {
+		if err := reportWriter.Progress(progress); err != nil {
+			logger.Warn().Err(err).Msg("Cannot write progress")
+		}
+	}, onGroup)
+
+	if onGroup == nil {
+		for _, group := range groups {
+			writeGroup(group)
+		}
+	}
+
+	if writeErr != nil {
+		logger.Fatal().Err(writeErr).Msg("Cannot write report")
+	}
+
+	if err := reportWriter.Finish(summary); err != nil {
+		logger.Fatal().Err(err).Msg("Cannot write report")
+	}
+
+	return groups, summary.Err == nil
+}
+
+// runServe scans the paths once and serves the report until the server is stopped
+func runServe(logger zerolog.Logger, params *parameters.RunParameters) bool {
+	info := newScanInfo(params)
+	groups, summary := scanPaths(logger, params, nil, nil)
+
+	handler := report.NewHandler(info, groups, summary, report.WriterOptions{FullFilePath: true})
+	logger.Info().Msgf("Serving the report at http://%s/report", params.Listen)
+	if err := http.ListenAndServe(params.Listen, handler); err != nil {
+		logger.Error().Err(err).Msg("Cannot serve report")
+		return false
+	}
+
+	return true
+}
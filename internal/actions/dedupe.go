@@ -0,0 +1,253 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/rs/zerolog"
+)
+
+// ErrFileChanged means the file was changed after the scan, such files are not touched
+var ErrFileChanged = errors.New("file changed after the scan")
+
+// ErrSameFile means the duplicate is the kept file found by another path, e.g. through a linked directory
+var ErrSameFile = errors.New("duplicate is the kept file")
+
+// Action replaces a duplicate of the kept file
+type Action interface {
+	// Name is a verb used in logs
+	Name() string
+	// Apply processes the duplicate and returns the number of reclaimed bytes
+	Apply(duplicate string, kept string, group *checkers.FilesCheckGroup) (int64, error)
+}
+
+// movingAction is implemented by the actions which keep the duplicate content at another path
+type movingAction interface {
+	// destination returns the new path of the duplicate
+	destination(duplicate string) (string, error)
+}
+
+// DedupeStats is the result of the deduplication
+type DedupeStats struct {
+	Groups         int   // Groups with the chosen file to keep
+	SkippedGroups  int   // Groups without the file to keep
+	Files          int   // Processed duplicates, planned ones for the dry run
+	FailedFiles    int   // Duplicates which cannot be processed
+	ReclaimedBytes int64 // Reclaimed space, expected one for the dry run
+}
+
+// Deduplicator keeps one file of every duplicated group and applies the action to the others
+type Deduplicator struct {
+	logger  zerolog.Logger
+	keeper  *Keeper
+	action  Action
+	journal *Journal // Journal of the applied changes, nil disables the journal
+	dryRun  bool     // Only log the planned actions
+}
+
+func NewDeduplicator(logger zerolog.Logger, keeper *Keeper, action Action, journal *Journal, dryRun bool) *Deduplicator {
+	return &Deduplicator{
+		logger:  logger,
+		keeper:  keeper,
+		action:  action,
+		journal: journal,
+		dryRun:  dryRun,
+	}
+}
+
+func (d *Deduplicator) Dedupe(groups []*checkers.FilesCheckGroup) DedupeStats {
+	var stats DedupeStats
+	for _, group := range groups {
+		files := group.Files()
+		kept, err := d.keeper.Choose(files)
+		if err != nil {
+			d.logger.Warn().
+				Str("hash", group.Hash()).
+				Msgf("Group skipped: %v", err)
+			stats.SkippedGroups++
+			continue
+		}
+
+		d.dedupeGroup(group, kept, &stats)
+	}
+
+	return stats
+}
+
+// DedupeDecisions processes the groups keeping the files chosen by the user
+func (d *Deduplicator) DedupeDecisions(decisions []Decision) DedupeStats {
+	var stats DedupeStats
+	for _, decision := range decisions {
+		d.dedupeGroup(decision.Group, decision.Kept, &stats)
+	}
+
+	return stats
+}
+
+func (d *Deduplicator) dedupeGroup(group *checkers.FilesCheckGroup, kept string, stats *DedupeStats) {
+	stats.Groups++
+	for _, file := range group.Files() {
+		if file == kept {
+			continue
+		}
+
+		reclaimed, err := d.process(file, kept, group)
+		if err != nil {
+			d.logger.Error().
+				Str("path", file).
+				Str("kept", kept).
+				Msgf("Cannot %s duplicate: %v", d.action.Name(), err)
+			stats.FailedFiles++
+			continue
+		}
+
+		stats.Files++
+		stats.ReclaimedBytes += reclaimed
+	}
+}
+
+func (d *Deduplicator) process(file string, kept string, group *checkers.FilesCheckGroup) (int64, error) {
+	if _, err := ensureUnchanged(kept, group.Size()); err != nil {
+		return 0, fmt.Errorf("kept file %s: %w", kept, err)
+	}
+
+	info, err := ensureUnchanged(file, group.Size())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ensureDistinct(file, kept); err != nil {
+		return 0, err
+	}
+
+	if d.dryRun {
+		d.logger.Info().
+			Str("path", file).
+			Str("kept", kept).
+			Msgf("Dry run: duplicate would be processed by %s action", d.action.Name())
+		return group.Size(), nil
+	}
+
+	target := kept
+	if moving, ok := d.action.(movingAction); ok {
+		if target, err = moving.destination(file); err != nil {
+			return 0, err
+		}
+	}
+
+	// The change is recorded before it is applied, so a crash in between leaves it undoable
+	var entry JournalEntry
+	if d.journal != nil {
+		entry = JournalEntry{
+			Time:          time.Now().UTC(),
+			Path:          file,
+			Action:        d.action.Name(),
+			Target:        target,
+			Hash:          group.Hash(),
+			HashAlgorithm: string(group.Algorithm()),
+			Size:          group.Size(),
+			Mode:          info.Mode(),
+			ModTime:       info.ModTime(),
+			Status:        JournalPending,
+		}
+		if err := d.journal.Record(entry); err != nil {
+			return 0, fmt.Errorf("duplicate is not processed, the change cannot be recorded: %w", err)
+		}
+	}
+
+	reclaimed, err := d.action.Apply(file, kept, group)
+
+	if d.journal != nil {
+		entry.Status = JournalApplied
+		if err != nil {
+			entry.Status = JournalFailed
+		}
+
+		if recordErr := d.journal.Record(entry); recordErr != nil {
+			d.logger.Warn().
+				Str("path", file).
+				Msgf("Cannot record the change status, the change is undone as a pending one: %v", recordErr)
+		}
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	d.logger.Info().
+		Str("path", file).
+		Str("kept", kept).
+		Msgf("Duplicate processed by %s action", d.action.Name())
+	return reclaimed, nil
+}
+
+// ensureUnchanged checks that the file is still a regular file of the expected size and returns its info
+func ensureUnchanged(path string, size int64) (os.FileInfo, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: not a regular file", ErrFileChanged)
+	}
+
+	if info.Size() != size {
+		return nil, fmt.Errorf("%w: size %d, expected %d", ErrFileChanged, info.Size(), size)
+	}
+
+	return info, nil
+}
+
+// ensureDistinct checks that the duplicate and the kept file are different directory entries.
+// The hard links have different real paths, so they are still processed.
+func ensureDistinct(duplicate string, kept string) error {
+	realDuplicate, err := realPath(duplicate)
+	if err != nil {
+		return err
+	}
+
+	realKept, err := realPath(kept)
+	if err != nil {
+		return fmt.Errorf("kept file %s: %w", kept, err)
+	}
+
+	if realDuplicate == realKept {
+		return fmt.Errorf("%w: both paths point to %s", ErrSameFile, realKept)
+	}
+
+	return nil
+}
+
+// realPath returns the absolute path with all the links resolved
+func realPath(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	return filepath.Abs(resolved)
+}
+
+// removeAction deletes the duplicates
+type removeAction struct{}
+
+func NewRemoveAction() Action {
+	return removeAction{}
+}
+
+func (removeAction) Name() string {
+	return "remove"
+}
+
+func (removeAction) Apply(duplicate string, kept string, group *checkers.FilesCheckGroup) (int64, error) {
+	if err := os.Remove(duplicate); err != nil {
+		return 0, err
+	}
+
+	return group.Size(), nil
+}
@@ -0,0 +1,116 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/pryazhnikov/gofileschecker/internal/testutil/filestest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDeduplicator(t *testing.T, action Action, dryRun bool) *Deduplicator {
+	t.Helper()
+
+	keeper, err := NewKeeper(KeepShortestPath, nil, "")
+	require.NoError(t, err)
+
+	return NewDeduplicator(zerolog.Nop(), keeper, action, nil, dryRun)
+}
+
+func TestDeduplicator_DryRun(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+	deduplicator := newTestDeduplicator(t, NewRemoveAction(), true)
+
+	// Act
+	stats := deduplicator.Dedupe(groups)
+
+	// Assert
+	assert.Equal(t, DedupeStats{Groups: 1, Files: 1, ReclaimedBytes: 7}, stats)
+	assert.FileExists(t, filepath.Join(dir, "a.txt"))
+	assert.FileExists(t, filepath.Join(dir, "dir/b.txt"), "Dry run should not change files")
+}
+
+func TestDeduplicator_Remove(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{
+		"a.txt":     "content",
+		"dir/b.txt": "content",
+		"dir/c.txt": "content",
+		"other.txt": "unique",
+	})
+	deduplicator := newTestDeduplicator(t, NewRemoveAction(), false)
+
+	// Act
+	stats := deduplicator.Dedupe(groups)
+
+	// Assert
+	assert.Equal(t, DedupeStats{Groups: 1, Files: 2, ReclaimedBytes: 14}, stats)
+	assert.FileExists(t, filepath.Join(dir, "a.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "dir/b.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "dir/c.txt"))
+	assert.FileExists(t, filepath.Join(dir, "other.txt"))
+}
+
+func TestDeduplicator_ChangedFilesAreNotTouched(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dir/b.txt"), []byte("changed content"), 0644))
+	deduplicator := newTestDeduplicator(t, NewRemoveAction(), false)
+
+	// Act
+	stats := deduplicator.Dedupe(groups)
+
+	// Assert
+	assert.Equal(t, DedupeStats{Groups: 1, FailedFiles: 1}, stats)
+	assert.FileExists(t, filepath.Join(dir, "dir/b.txt"))
+}
+
+func TestDeduplicator_SameFileByAnotherPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(t *testing.T) Action
+	}{
+		{name: "remove", action: func(t *testing.T) Action { return NewRemoveAction() }},
+		{name: "symlink", action: func(t *testing.T) Action { return NewSymlinkAction(LinkRelative) }},
+		{
+			name: "quarantine",
+			action: func(t *testing.T) Action {
+				action, err := NewQuarantineAction(t.TempDir())
+				require.NoError(t, err)
+				return action
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			filestest.WriteFiles(t, dir, map[string]string{"real/a.txt": "content"})
+			require.NoError(t, os.Symlink(filepath.Join(dir, "real"), filepath.Join(dir, "link")))
+
+			fc, err := checkers.NewFileChecker(checkers.Options{})
+			require.NoError(t, err)
+			filestest.CheckFiles(t, fc, map[string]string{
+				"real/a.txt": filepath.Join(dir, "real", "a.txt"),
+				"link/a.txt": filepath.Join(dir, "link", "a.txt"),
+			})
+			deduplicator := newTestDeduplicator(t, tt.action(t), false)
+
+			// Act
+			stats := deduplicator.Dedupe(fc.GetDuplicatedFileGroups())
+
+			// Assert
+			assert.Equal(t, DedupeStats{Groups: 1, FailedFiles: 1}, stats)
+			content, err := os.ReadFile(filepath.Join(dir, "real", "a.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, "content", string(content), "The only copy of the file should be kept")
+		})
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package actions
+
+import "os"
+
+// sameDevice cannot get the device on this platform, the link creation reports the error if any
+func sameDevice(a, b os.FileInfo) bool {
+	return true
+}
+
+// hasOtherLinks cannot get the links count on this platform, the file is considered the only link
+func hasOtherLinks(info os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package actions
+
+import (
+	"os"
+	"syscall"
+)
+
+// sameDevice checks if the files are located on the same filesystem
+func sameDevice(a, b os.FileInfo) bool {
+	statA, okA := a.Sys().(*syscall.Stat_t)
+	statB, okB := b.Sys().(*syscall.Stat_t)
+	if !okA || !okB {
+		return true // Unknown, the link creation reports the error if any
+	}
+
+	return statA.Dev == statB.Dev
+}
+
+// hasOtherLinks checks if the file data is also referenced by other hard links
+func hasOtherLinks(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Nlink > 1
+}
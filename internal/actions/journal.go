@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// JournalEntry describes a change of a duplicate, it is enough to reverse the change when possible
+type JournalEntry struct {
+	Time          time.Time     `json:"time"`
+	Path          string        `json:"path"`   // Original path of the duplicate
+	Action        string        `json:"action"` // Name of the applied action
+	Target        string        `json:"target"` // The kept file, or the new location of the moved duplicate
+	Hash          string        `json:"hash"`
+	HashAlgorithm string        `json:"hash_algorithm"`
+	Size          int64         `json:"size"`
+	Mode          os.FileMode   `json:"mode"`
+	ModTime       time.Time     `json:"mtime"`
+	Status        JournalStatus `json:"status,omitempty"`
+}
+
+// JournalStatus is the state of the recorded change, the entries without the status are applied ones
+type JournalStatus string
+
+const (
+	JournalPending JournalStatus = "pending" // Recorded before the change, it may be applied or not
+	JournalApplied JournalStatus = "applied"
+	JournalFailed  JournalStatus = "failed" // The change was not applied, nothing to undo
+)
+
+// DefaultJournalPath returns the journal path in the working directory used when no journal is set,
+// the time makes the journals of the different runs separate
+func DefaultJournalPath(now time.Time) string {
+	return fmt.Sprintf("gofileschecker-journal-%s.ndjson", now.Format("20060102-150405"))
+}
+
+// Journal is an append-only file of the applied changes, one JSON entry per line
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenJournal opens the journal for appending, the file is created if it does not exist
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	return &Journal{file: file}, nil
+}
+
+// Record appends the entry and flushes it to the disk, so the entry survives a crash of the next change
+func (j *Journal) Record(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	return j.file.Sync()
+}
+
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// ReadJournal returns the journal entries of the changes in the order of their recording.
+// Every change is recorded before it is applied and again with its status after,
+// the latest status wins and the failed changes are dropped.
+func ReadJournal(path string) ([]JournalEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	positions := make(map[journalKey]int)
+	lines := bufio.NewScanner(file)
+	lines.Buffer(nil, 1024*1024) // Paths are limited by the filesystem, but long lines are allowed
+	for lineNumber := 1; lines.Scan(); lineNumber++ {
+		if len(lines.Bytes()) == 0 {
+			continue
+		}
+
+		var entry JournalEntry
+		if err := json.Unmarshal(lines.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid journal line %d: %w", lineNumber, err)
+		}
+
+		key := journalKey{time: entry.Time.UnixNano(), path: entry.Path}
+		if i, ok := positions[key]; ok {
+			entries[i].Status = entry.Status
+			continue
+		}
+
+		positions[key] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return slices.DeleteFunc(entries, func(entry JournalEntry) bool {
+		return entry.Status == JournalFailed
+	}), nil
+}
+
+// journalKey identifies the change among the entries of its statuses
+type journalKey struct {
+	time int64 // Nanoseconds of the change time
+	path string
+}
@@ -0,0 +1,148 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// KeepPolicy defines which file of a duplicated group is kept
+type KeepPolicy string
+
+const (
+	KeepOldest       KeepPolicy = "oldest"        // The file with the oldest modification time
+	KeepNewest       KeepPolicy = "newest"        // The file with the newest modification time
+	KeepShortestPath KeepPolicy = "shortest-path" // The file with the shortest path
+	KeepLongestPath  KeepPolicy = "longest-path"  // The file with the longest path
+	KeepFirstRoot    KeepPolicy = "first-root"    // The file from the first scanned path
+	KeepRegex        KeepPolicy = "regex"         // The first file matching the regular expression
+)
+
+var keepPolicies = []KeepPolicy{KeepOldest, KeepNewest, KeepShortestPath, KeepLongestPath, KeepFirstRoot, KeepRegex}
+
+// ErrNoFileToKeep means the policy cannot choose a file, such groups should not be changed
+var ErrNoFileToKeep = errors.New("no file to keep")
+
+func ParseKeepPolicy(name string) (KeepPolicy, error) {
+	for _, policy := range keepPolicies {
+		if string(policy) == name {
+			return policy, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown keep policy: %q", name)
+}
+
+// Keeper chooses the file to keep in a duplicated files group.
+// The files are expected in lexical order, the first one wins on ties.
+type Keeper struct {
+	policy  KeepPolicy
+	roots   []string       // Absolute scanned paths for KeepFirstRoot
+	pattern *regexp.Regexp // Pattern for KeepRegex
+}
+
+func NewKeeper(policy KeepPolicy, roots []string, pattern string) (*Keeper, error) {
+	keeper := &Keeper{policy: policy}
+
+	switch policy {
+	case KeepFirstRoot:
+		for _, root := range roots {
+			absRoot, err := filepath.Abs(root)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get absolute path: %w", err)
+			}
+
+			keeper.roots = append(keeper.roots, absRoot)
+		}
+	case KeepRegex:
+		if pattern == "" {
+			return nil, fmt.Errorf("pattern is required for %s keep policy", policy)
+		}
+
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keep pattern: %w", err)
+		}
+
+		keeper.pattern = compiled
+	}
+
+	return keeper, nil
+}
+
+func (k *Keeper) Policy() KeepPolicy {
+	return k.policy
+}
+
+// Choose returns the file to keep
+func (k *Keeper) Choose(files []string) (string, error) {
+	if len(files) == 0 {
+		return "", ErrNoFileToKeep
+	}
+
+	switch k.policy {
+	case KeepOldest:
+		return chooseByModTime(files, func(a, b time.Time) bool { return a.Before(b) })
+	case KeepNewest:
+		return chooseByModTime(files, func(a, b time.Time) bool { return a.After(b) })
+	case KeepShortestPath:
+		return chooseBest(files, func(a, b string) bool { return len(a) < len(b) }), nil
+	case KeepLongestPath:
+		return chooseBest(files, func(a, b string) bool { return len(a) > len(b) }), nil
+	case KeepFirstRoot:
+		return k.chooseFirstRoot(files)
+	case KeepRegex:
+		for _, file := range files {
+			if k.pattern.MatchString(file) {
+				return file, nil
+			}
+		}
+
+		return "", fmt.Errorf("%w: no file matches %q", ErrNoFileToKeep, k.pattern.String())
+	}
+
+	return "", fmt.Errorf("unknown keep policy: %q", string(k.policy))
+}
+
+// chooseBest returns the first file which is not worse than all the others
+func chooseBest(files []string, better func(a, b string) bool) string {
+	best := files[0]
+	for _, file := range files[1:] {
+		if better(file, best) {
+			best = file
+		}
+	}
+
+	return best
+}
+
+func chooseByModTime(files []string, better func(a, b time.Time) bool) (string, error) {
+	modTimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrNoFileToKeep, err)
+		}
+
+		modTimes[file] = info.ModTime()
+	}
+
+	return chooseBest(files, func(a, b string) bool { return better(modTimes[a], modTimes[b]) }), nil
+}
+
+func (k *Keeper) chooseFirstRoot(files []string) (string, error) {
+	for _, root := range k.roots {
+		prefix := strings.TrimSuffix(root, string(filepath.Separator)) + string(filepath.Separator)
+		for _, file := range files {
+			if strings.HasPrefix(file, prefix) {
+				return file, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: no file belongs to the scanned paths", ErrNoFileToKeep)
+}
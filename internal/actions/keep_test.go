@@ -0,0 +1,99 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeepPolicy(t *testing.T) {
+	for _, policy := range keepPolicies {
+		parsed, err := ParseKeepPolicy(string(policy))
+		require.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+
+	_, err := ParseKeepPolicy("random")
+	assert.Error(t, err)
+}
+
+func TestKeeper_Choose(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		filepath.Join(dir, "first/a.txt"),
+		filepath.Join(dir, "first/long/b.txt"),
+		filepath.Join(dir, "second/c.txt"),
+		filepath.Join(dir, "second/d.txt"),
+	}
+
+	// The modification times are set in the order different from the files order
+	modTimes := []time.Duration{2 * time.Hour, 3 * time.Hour, time.Hour, 4 * time.Hour}
+	for idx, file := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, os.WriteFile(file, []byte("content"), 0644))
+		modTime := time.Now().Add(-modTimes[idx])
+		require.NoError(t, os.Chtimes(file, modTime, modTime))
+	}
+
+	tests := []struct {
+		name     string
+		policy   KeepPolicy
+		roots    []string
+		pattern  string
+		expected string
+	}{
+		{name: "oldest", policy: KeepOldest, expected: files[3]},
+		{name: "newest", policy: KeepNewest, expected: files[2]},
+		{name: "shortest path, the first one on tie", policy: KeepShortestPath, expected: files[0]},
+		{name: "longest path", policy: KeepLongestPath, expected: files[1]},
+		{
+			name:     "first root",
+			policy:   KeepFirstRoot,
+			roots:    []string{filepath.Join(dir, "second"), filepath.Join(dir, "first")},
+			expected: files[2],
+		},
+		{name: "regex", policy: KeepRegex, pattern: `second/d\.txt$`, expected: files[3]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keeper, err := NewKeeper(tt.policy, tt.roots, tt.pattern)
+			require.NoError(t, err)
+
+			kept, err := keeper.Choose(files)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, kept)
+		})
+	}
+}
+
+func TestKeeper_NoFileToKeep(t *testing.T) {
+	files := []string{"/data/a.txt", "/data/b.txt"}
+
+	keeper, err := NewKeeper(KeepRegex, nil, `\.jpg$`)
+	require.NoError(t, err)
+	_, err = keeper.Choose(files)
+	assert.ErrorIs(t, err, ErrNoFileToKeep)
+
+	keeper, err = NewKeeper(KeepFirstRoot, []string{"/other"}, "")
+	require.NoError(t, err)
+	_, err = keeper.Choose(files)
+	assert.ErrorIs(t, err, ErrNoFileToKeep, "Root prefix should match whole directory names only")
+
+	keeper, err = NewKeeper(KeepOldest, nil, "")
+	require.NoError(t, err)
+	_, err = keeper.Choose(files)
+	assert.ErrorIs(t, err, ErrNoFileToKeep, "Missing files cannot be compared")
+}
+
+func TestNewKeeper_InvalidPattern(t *testing.T) {
+	_, err := NewKeeper(KeepRegex, nil, "")
+	assert.Error(t, err)
+
+	_, err = NewKeeper(KeepRegex, nil, "(")
+	assert.Error(t, err)
+}
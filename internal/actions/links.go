@@ -0,0 +1,141 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+)
+
+// The number of attempts to find an unused temporary name
+const tempNameAttempts = 10
+
+// ErrCrossDevice means the files are located on different filesystems
+var ErrCrossDevice = errors.New("files are on different filesystems")
+
+// hardlinkAction replaces the duplicates with hard links to the kept file
+type hardlinkAction struct{}
+
+func NewHardlinkAction() Action {
+	return hardlinkAction{}
+}
+
+func (hardlinkAction) Name() string {
+	return "hardlink"
+}
+
+func (hardlinkAction) Apply(duplicate string, kept string, group *checkers.FilesCheckGroup) (int64, error) {
+	duplicateInfo, err := os.Stat(duplicate)
+	if err != nil {
+		return 0, err
+	}
+
+	keptInfo, err := os.Stat(kept)
+	if err != nil {
+		return 0, err
+	}
+
+	if os.SameFile(duplicateInfo, keptInfo) {
+		return 0, nil // Already linked, nothing to reclaim
+	}
+
+	if !sameDevice(duplicateInfo, keptInfo) {
+		return 0, ErrCrossDevice
+	}
+
+	err = replaceAtomically(duplicate, func(tempPath string) error {
+		return os.Link(kept, tempPath)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if hasOtherLinks(duplicateInfo) {
+		return 0, nil // The data is still referenced by the other links of the duplicate
+	}
+
+	return group.Size(), nil
+}
+
+// symlinkAction replaces the duplicates with symbolic links to the kept file
+type symlinkAction struct {
+	style LinkStyle
+}
+
+func NewSymlinkAction(style LinkStyle) Action {
+	return symlinkAction{style: style}
+}
+
+func (symlinkAction) Name() string {
+	return "symlink"
+}
+
+func (a symlinkAction) Apply(duplicate string, kept string, group *checkers.FilesCheckGroup) (int64, error) {
+	target, err := a.linkTarget(duplicate, kept)
+	if err != nil {
+		return 0, err
+	}
+
+	err = replaceAtomically(duplicate, func(tempPath string) error {
+		return os.Symlink(target, tempPath)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return group.Size(), nil
+}
+
+// linkTarget returns the path the link should point to
+func (a symlinkAction) linkTarget(duplicate string, kept string) (string, error) {
+	absKept, err := filepath.Abs(kept)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	if a.style == LinkAbsolute {
+		return absKept, nil
+	}
+
+	absDuplicate, err := filepath.Abs(duplicate)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	target, err := filepath.Rel(filepath.Dir(absDuplicate), absKept)
+	if err != nil {
+		return "", fmt.Errorf("failed to get relative path: %w", err)
+	}
+
+	return target, nil
+}
+
+// replaceAtomically creates the replacement under a temporary name in the target directory
+// and renames it over the target, so the target path always points to a complete file.
+func replaceAtomically(target string, create func(tempPath string) error) error {
+	dir, name := filepath.Split(target)
+	for attempt := 0; attempt < tempNameAttempts; attempt++ {
+		tempPath := filepath.Join(dir, fmt.Sprintf(".%s.%08x.tmp", name, rand.Uint32()))
+
+		err := create(tempPath)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to create replacement: %w", err)
+		}
+
+		if err := os.Rename(tempPath, target); err != nil {
+			os.Remove(tempPath)
+			return fmt.Errorf("failed to replace file: %w", err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("failed to find unused temporary name for %s", target)
+}
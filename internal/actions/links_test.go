@@ -0,0 +1,116 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDedupeMode(t *testing.T) {
+	for _, mode := range dedupeModes {
+		parsed, err := ParseDedupeMode(string(mode))
+		require.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+
+		action, err := NewAction(mode, ActionOptions{QuarantineDir: t.TempDir()})
+		require.NoError(t, err)
+		assert.NotNil(t, action)
+	}
+
+	_, err := ParseDedupeMode("move")
+	assert.Error(t, err)
+}
+
+func TestDeduplicator_Hardlink(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+	deduplicator := newTestDeduplicator(t, NewHardlinkAction(), false)
+
+	// Act
+	stats := deduplicator.Dedupe(groups)
+
+	// Assert
+	assert.Equal(t, DedupeStats{Groups: 1, Files: 1, ReclaimedBytes: 7}, stats)
+
+	keptInfo, err := os.Stat(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	linkInfo, err := os.Stat(filepath.Join(dir, "dir/b.txt"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(keptInfo, linkInfo), "The duplicate should be a hard link to the kept file")
+
+	entries, err := os.ReadDir(filepath.Join(dir, "dir"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "No temporary files should be left")
+
+	// The second run finds nothing to reclaim
+	stats = deduplicator.Dedupe(groups)
+	assert.Equal(t, DedupeStats{Groups: 1, Files: 1, ReclaimedBytes: 0}, stats)
+}
+
+func TestReplaceAtomically_KeepsTargetOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	require.NoError(t, os.WriteFile(target, []byte("original"), 0644))
+
+	err := replaceAtomically(target, func(tempPath string) error {
+		return os.Link(filepath.Join(dir, "missing.txt"), tempPath)
+	})
+	require.Error(t, err)
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "No temporary files should be left")
+}
+
+func TestDeduplicator_Symlink(t *testing.T) {
+	tests := []struct {
+		name           string
+		style          LinkStyle
+		expectedTarget func(dir string) string
+	}{
+		{name: "relative", style: LinkRelative, expectedTarget: func(dir string) string { return "../../a.txt" }},
+		{name: "absolute", style: LinkAbsolute, expectedTarget: func(dir string) string { return filepath.Join(dir, "a.txt") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/sub/b.txt": "content"})
+			deduplicator := newTestDeduplicator(t, NewSymlinkAction(tt.style), false)
+
+			// Act
+			stats := deduplicator.Dedupe(groups)
+
+			// Assert
+			assert.Equal(t, DedupeStats{Groups: 1, Files: 1, ReclaimedBytes: 7}, stats)
+
+			linkPath := filepath.Join(dir, "dir/sub/b.txt")
+			target, err := os.Readlink(linkPath)
+			require.NoError(t, err, "The duplicate should be a symbolic link")
+			assert.Equal(t, tt.expectedTarget(dir), target)
+
+			content, err := os.ReadFile(linkPath)
+			require.NoError(t, err)
+			assert.Equal(t, "content", string(content))
+		})
+	}
+}
+
+func TestParseLinkStyle(t *testing.T) {
+	for _, style := range []LinkStyle{LinkRelative, LinkAbsolute} {
+		parsed, err := ParseLinkStyle(string(style))
+		require.NoError(t, err)
+		assert.Equal(t, style, parsed)
+	}
+
+	_, err := ParseLinkStyle("hard")
+	assert.Error(t, err)
+}
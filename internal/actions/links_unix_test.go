@@ -0,0 +1,32 @@
+//go:build unix
+
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicator_HardlinkOfLinkedDuplicate(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+	require.NoError(t, os.Link(filepath.Join(dir, "dir/b.txt"), filepath.Join(t.TempDir(), "other.txt")))
+	deduplicator := newTestDeduplicator(t, NewHardlinkAction(), false)
+
+	// Act
+	stats := deduplicator.Dedupe(groups)
+
+	// Assert
+	assert.Equal(t, DedupeStats{Groups: 1, Files: 1, ReclaimedBytes: 0}, stats, "The other link still holds the data")
+
+	keptInfo, err := os.Stat(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	linkInfo, err := os.Stat(filepath.Join(dir, "dir/b.txt"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(keptInfo, linkInfo), "The duplicate should be a hard link to the kept file")
+}
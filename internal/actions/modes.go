@@ -0,0 +1,71 @@
+package actions
+
+import "fmt"
+
+// DedupeMode defines what is done with the duplicates which are not kept
+type DedupeMode string
+
+const (
+	DedupeRemove     DedupeMode = "remove"     // Delete the duplicates
+	DedupeHardlink   DedupeMode = "hardlink"   // Replace the duplicates with hard links to the kept file
+	DedupeSymlink    DedupeMode = "symlink"    // Replace the duplicates with symbolic links to the kept file
+	DedupeReflink    DedupeMode = "reflink"    // Share data extents of the duplicates with the kept file (Linux only)
+	DedupeQuarantine DedupeMode = "quarantine" // Move the duplicates into the quarantine directory
+)
+
+var dedupeModes = []DedupeMode{DedupeRemove, DedupeHardlink, DedupeSymlink, DedupeReflink, DedupeQuarantine}
+
+// LinkStyle defines how symbolic links point to the kept file
+type LinkStyle string
+
+const (
+	LinkRelative LinkStyle = "relative" // The path relative to the link directory
+	LinkAbsolute LinkStyle = "absolute" // The absolute path
+)
+
+func ParseLinkStyle(name string) (LinkStyle, error) {
+	switch style := LinkStyle(name); style {
+	case LinkRelative, LinkAbsolute:
+		return style, nil
+	}
+
+	return "", fmt.Errorf("unknown link style: %q", name)
+}
+
+type ActionOptions struct {
+	LinkStyle     LinkStyle // Style of symbolic links, LinkRelative is used if empty
+	QuarantineDir string    // Directory for the quarantined duplicates, required by DedupeQuarantine
+}
+
+func ParseDedupeMode(name string) (DedupeMode, error) {
+	for _, mode := range dedupeModes {
+		if string(mode) == name {
+			return mode, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown dedupe mode: %q", name)
+}
+
+// NewAction creates the action for the dedupe mode
+func NewAction(mode DedupeMode, options ActionOptions) (Action, error) {
+	switch mode {
+	case DedupeRemove:
+		return NewRemoveAction(), nil
+	case DedupeHardlink:
+		return NewHardlinkAction(), nil
+	case DedupeSymlink:
+		style := options.LinkStyle
+		if style == "" {
+			style = LinkRelative
+		}
+
+		return NewSymlinkAction(style), nil
+	case DedupeReflink:
+		return NewReflinkAction(), nil
+	case DedupeQuarantine:
+		return NewQuarantineAction(options.QuarantineDir)
+	}
+
+	return nil, fmt.Errorf("unknown dedupe mode: %q", string(mode))
+}
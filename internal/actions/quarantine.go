@@ -0,0 +1,171 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+)
+
+// QuarantineManifestName is the name of the manifest file in the quarantine directory
+const QuarantineManifestName = "manifest.ndjson"
+
+// QuarantineEntry is a line of the quarantine manifest, it allows to restore the moved file
+type QuarantineEntry struct {
+	OriginalPath   string    `json:"original_path"`
+	QuarantinePath string    `json:"quarantine_path"`
+	Kept           string    `json:"kept"`
+	Hash           string    `json:"hash"`
+	HashAlgorithm  string    `json:"hash_algorithm"`
+	Size           int64     `json:"size"`
+	QuarantinedAt  time.Time `json:"quarantined_at"`
+}
+
+// quarantineAction moves the duplicates into the quarantine directory mirroring their absolute paths
+type quarantineAction struct {
+	dir string // Absolute path of the quarantine directory
+}
+
+func NewQuarantineAction(dir string) (Action, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("quarantine directory is not set")
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	return quarantineAction{dir: absDir}, nil
+}
+
+func (quarantineAction) Name() string {
+	return "quarantine"
+}
+
+func (a quarantineAction) Apply(duplicate string, kept string, group *checkers.FilesCheckGroup) (int64, error) {
+	absDuplicate, err := filepath.Abs(duplicate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	target := a.quarantinePath(absDuplicate)
+	if _, err := os.Lstat(target); err == nil {
+		return 0, fmt.Errorf("%w: quarantined file %s", os.ErrExist, target)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+
+	if err := moveFile(absDuplicate, target); err != nil {
+		return 0, err
+	}
+
+	err = a.appendManifest(QuarantineEntry{
+		OriginalPath:   absDuplicate,
+		QuarantinePath: target,
+		Kept:           kept,
+		Hash:           group.Hash(),
+		HashAlgorithm:  string(group.Algorithm()),
+		Size:           group.Size(),
+		QuarantinedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		// The file cannot be restored without the manifest entry, moving it back
+		if restoreErr := moveFile(target, absDuplicate); restoreErr != nil {
+			return 0, fmt.Errorf("failed to write manifest: %w, file is left at %s: %v", err, target, restoreErr)
+		}
+
+		return 0, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return group.Size(), nil
+}
+
+func (a quarantineAction) destination(duplicate string) (string, error) {
+	absDuplicate, err := filepath.Abs(duplicate)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	return a.quarantinePath(absDuplicate), nil
+}
+
+// quarantinePath returns the path of the file inside the quarantine directory
+func (a quarantineAction) quarantinePath(absPath string) string {
+	// Windows drive letters cannot be a part of the path, "C:" becomes "C"
+	volume := filepath.VolumeName(absPath)
+	return filepath.Join(a.dir, strings.TrimSuffix(volume, ":"), absPath[len(volume):])
+}
+
+func (a quarantineAction) appendManifest(entry QuarantineEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := os.OpenFile(filepath.Join(a.dir, QuarantineManifestName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = manifest.Write(append(line, '\n'))
+	if closeErr := manifest.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// moveFile renames the file, the file is copied if the target is on another filesystem
+func moveFile(source string, target string) error {
+	err := os.Rename(source, target)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := copyFile(source, target); err != nil {
+		os.Remove(target)
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return os.Remove(source)
+}
+
+// copyFile copies the file content keeping its mode and modification time
+func copyFile(source string, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Chtimes(target, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,85 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicator_Quarantine(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+	quarantineDir := t.TempDir()
+	action, err := NewQuarantineAction(quarantineDir)
+	require.NoError(t, err)
+	deduplicator := newTestDeduplicator(t, action, false)
+
+	// Act
+	stats := deduplicator.Dedupe(groups)
+
+	// Assert
+	assert.Equal(t, DedupeStats{Groups: 1, Files: 1, ReclaimedBytes: 7}, stats)
+
+	duplicate := filepath.Join(dir, "dir/b.txt")
+	assert.NoFileExists(t, duplicate)
+	assert.FileExists(t, filepath.Join(dir, "a.txt"))
+
+	quarantined := filepath.Join(quarantineDir, duplicate)
+	content, err := os.ReadFile(quarantined)
+	require.NoError(t, err, "The duplicate should mirror its absolute path")
+	assert.Equal(t, "content", string(content))
+
+	manifest, err := os.Open(filepath.Join(quarantineDir, QuarantineManifestName))
+	require.NoError(t, err)
+	defer manifest.Close()
+
+	var entries []QuarantineEntry
+	lines := bufio.NewScanner(manifest)
+	for lines.Scan() {
+		var entry QuarantineEntry
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	require.Len(t, entries, 1)
+	assert.Equal(t, duplicate, entries[0].OriginalPath)
+	assert.Equal(t, quarantined, entries[0].QuarantinePath)
+	assert.Equal(t, groups[0].Hash(), entries[0].Hash)
+	assert.Equal(t, int64(7), entries[0].Size)
+	assert.False(t, entries[0].QuarantinedAt.IsZero())
+}
+
+func TestQuarantineAction_KeepsQuarantinedFile(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+	quarantineDir := t.TempDir()
+	duplicate := filepath.Join(dir, "dir/b.txt")
+	quarantined := filepath.Join(quarantineDir, duplicate)
+	require.NoError(t, os.MkdirAll(filepath.Dir(quarantined), 0755))
+	require.NoError(t, os.WriteFile(quarantined, []byte("earlier"), 0644))
+
+	action, err := NewQuarantineAction(quarantineDir)
+	require.NoError(t, err)
+
+	// Act
+	_, err = action.Apply(duplicate, filepath.Join(dir, "a.txt"), groups[0])
+
+	// Assert
+	require.ErrorIs(t, err, os.ErrExist)
+	assert.FileExists(t, duplicate)
+
+	content, err := os.ReadFile(quarantined)
+	require.NoError(t, err)
+	assert.Equal(t, "earlier", string(content), "The earlier quarantined file should not be overwritten")
+}
+
+func TestNewQuarantineAction_RequiresDirectory(t *testing.T) {
+	_, err := NewQuarantineAction("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+package actions
+
+import (
+	"errors"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+)
+
+// ErrReflinkUnsupported means the filesystem (or the platform) cannot share data between files
+var ErrReflinkUnsupported = errors.New("reflink is not supported")
+
+// reflinkAction makes the duplicates share data extents with the kept file.
+// The duplicates stay independent files, a change of one file does not affect the others.
+type reflinkAction struct{}
+
+func NewReflinkAction() Action {
+	return reflinkAction{}
+}
+
+func (reflinkAction) Name() string {
+	return "reflink"
+}
+
+func (reflinkAction) Apply(duplicate string, kept string, group *checkers.FilesCheckGroup) (int64, error) {
+	if err := reflinkFile(duplicate, kept, group.Size()); err != nil {
+		return 0, err
+	}
+
+	return group.Size(), nil
+}
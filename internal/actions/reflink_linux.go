@@ -0,0 +1,109 @@
+//go:build linux
+
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Some filesystems limit the length of a single FIDEDUPERANGE call
+const dedupeChunkSize = 16 * 1024 * 1024
+
+// reflinkFile shares the kept file extents with the duplicate using FIDEDUPERANGE ioctl,
+// the kernel compares the contents and shares the data only if they are equal.
+// If it is not available, the duplicate is replaced with a FICLONE copy of the kept file.
+func reflinkFile(duplicate string, kept string, size int64) error {
+	src, err := os.Open(kept)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	err = dedupeRange(src, duplicate, size)
+	if err == nil || !isReflinkUnsupported(err) {
+		return err
+	}
+
+	err = cloneFile(src, duplicate)
+	if isReflinkUnsupported(err) {
+		return fmt.Errorf("%w: %w", ErrReflinkUnsupported, err)
+	}
+
+	return err
+}
+
+func dedupeRange(src *os.File, duplicate string, size int64) error {
+	dst, err := os.OpenFile(duplicate, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	for offset := uint64(0); offset < uint64(size); {
+		dedupe := unix.FileDedupeRange{
+			Src_offset: offset,
+			Src_length: min(dedupeChunkSize, uint64(size)-offset),
+			Info:       []unix.FileDedupeRangeInfo{{Dest_fd: int64(dst.Fd()), Dest_offset: offset}},
+		}
+		if err := unix.IoctlFileDedupeRange(int(src.Fd()), &dedupe); err != nil {
+			return err
+		}
+
+		info := dedupe.Info[0]
+		switch {
+		case info.Status == unix.FILE_DEDUPE_RANGE_DIFFERS:
+			return fmt.Errorf("%w: content differs at offset %d", ErrFileChanged, offset)
+		case info.Status < 0:
+			return unix.Errno(-info.Status)
+		case info.Bytes_deduped == 0:
+			return fmt.Errorf("no bytes deduplicated at offset %d", offset)
+		}
+
+		offset += info.Bytes_deduped
+	}
+
+	return nil
+}
+
+// cloneFile replaces the duplicate with a clone of the source file keeping the duplicate mode and times
+func cloneFile(src *os.File, duplicate string) error {
+	info, err := os.Stat(duplicate)
+	if err != nil {
+		return err
+	}
+
+	return replaceAtomically(duplicate, func(tempPath string) error {
+		dst, err := os.OpenFile(tempPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+
+		err = unix.IoctlFileClone(int(dst.Fd()), int(src.Fd()))
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err == nil {
+			err = os.Chtimes(tempPath, info.ModTime(), info.ModTime())
+		}
+
+		if err != nil {
+			os.Remove(tempPath)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// isReflinkUnsupported checks if the ioctl error means the filesystem cannot share extents
+func isReflinkUnsupported(err error) bool {
+	return errors.Is(err, unix.EOPNOTSUPP) ||
+		errors.Is(err, unix.ENOTTY) ||
+		errors.Is(err, unix.EXDEV) ||
+		errors.Is(err, unix.EINVAL)
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package actions
+
+// reflinkFile is implemented for Linux only
+func reflinkFile(duplicate string, kept string, size int64) error {
+	return ErrReflinkUnsupported
+}
@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReflinkAction(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+	duplicate := filepath.Join(dir, "dir/b.txt")
+	kept := filepath.Join(dir, "a.txt")
+
+	// Act
+	reclaimed, err := NewReflinkAction().Apply(duplicate, kept, groups[0])
+
+	// Assert
+	if errors.Is(err, ErrReflinkUnsupported) {
+		// The duplicate should not be touched on filesystems without the shared extents support
+		content, readErr := os.ReadFile(duplicate)
+		require.NoError(t, readErr)
+		assert.Equal(t, "content", string(content))
+		t.Skipf("Reflink is not supported by the test filesystem: %v", err)
+	}
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), reclaimed)
+
+	keptInfo, err := os.Stat(kept)
+	require.NoError(t, err)
+	info, err := os.Stat(duplicate)
+	require.NoError(t, err)
+	assert.False(t, os.SameFile(keptInfo, info), "The duplicate should stay a separate file")
+
+	content, err := os.ReadFile(duplicate)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "dir"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "No temporary files should be left")
+}
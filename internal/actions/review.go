@@ -0,0 +1,220 @@
+package actions
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+)
+
+// Decision is the file chosen to keep in the reviewed group
+type Decision struct {
+	Group *checkers.FilesCheckGroup
+	Kept  string
+}
+
+// reviewChoice is remembered for all groups with the same common path prefix
+type reviewChoice struct {
+	skip    bool
+	keptDir string // Directory of the kept file relative to the common prefix
+}
+
+// Reviewer asks the user which file of every duplicated group should be kept
+type Reviewer struct {
+	in      *bufio.Reader
+	out     io.Writer
+	keeper  *Keeper                 // Suggests the default choice
+	choices map[string]reviewChoice // Choices applied to all groups by their common path prefix
+}
+
+func NewReviewer(in io.Reader, out io.Writer, keeper *Keeper) *Reviewer {
+	return &Reviewer{
+		in:      bufio.NewReader(in),
+		out:     out,
+		keeper:  keeper,
+		choices: make(map[string]reviewChoice),
+	}
+}
+
+// Review walks through the groups and returns the decisions for the groups which are not skipped.
+// The review stops early if the user quits or the input is closed.
+func (r *Reviewer) Review(groups []*checkers.FilesCheckGroup) ([]Decision, error) {
+	var decisions []Decision
+	for i, group := range groups {
+		files := group.Files()
+		prefix := group.CommonPathPrefix()
+		fmt.Fprintf(r.out, "\nGroup %d of %d: %s:%s, %d bytes each, common prefix: %s\n",
+			i+1, len(groups), group.Algorithm(), group.Hash(), group.Size(), strconv.Quote(prefix))
+
+		if choice, ok := r.choices[prefix]; ok {
+			kept, ok := choice.apply(files, prefix)
+			if ok {
+				r.printApplied(kept)
+				if kept != "" {
+					decisions = append(decisions, Decision{Group: group, Kept: kept})
+				}
+				continue
+			}
+		}
+
+		suggested, _ := r.keeper.Choose(files)
+		r.printFiles(files, suggested)
+
+		kept, quit, err := r.ask(files, suggested, prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		if quit {
+			fmt.Fprintf(r.out, "Review stopped, %d groups are left unchanged\n", len(groups)-i)
+			break
+		}
+
+		if kept != "" {
+			decisions = append(decisions, Decision{Group: group, Kept: kept})
+		}
+	}
+
+	return decisions, nil
+}
+
+// Confirm asks the user to apply the decisions
+func (r *Reviewer) Confirm(decisions []Decision) (bool, error) {
+	files := 0
+	for _, decision := range decisions {
+		files += decision.Group.FilesCount() - 1
+	}
+
+	fmt.Fprintf(r.out, "\nProcess %d duplicates in %d groups? [y/N] ", files, len(decisions))
+	answer, err := r.readLine()
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return answer == "y" || answer == "yes", nil
+}
+
+func (r *Reviewer) PrintSummary(stats DedupeStats) {
+	fmt.Fprintf(r.out, "Processed duplicates: %d, failed: %d, groups: %d, skipped groups: %d, reclaimed bytes: %d\n",
+		stats.Files, stats.FailedFiles, stats.Groups, stats.SkippedGroups, stats.ReclaimedBytes)
+}
+
+func (r *Reviewer) printFiles(files []string, suggested string) {
+	for i, file := range files {
+		marker := " "
+		if file == suggested {
+			marker = "*"
+		}
+
+		modTime := "unknown"
+		if info, err := os.Stat(file); err == nil {
+			modTime = info.ModTime().Format(time.DateTime)
+		}
+
+		fmt.Fprintf(r.out, " %s[%d] %s (modified %s)\n", marker, i+1, strconv.Quote(file), modTime)
+	}
+}
+
+func (r *Reviewer) printApplied(kept string) {
+	if kept == "" {
+		fmt.Fprintln(r.out, "Skipped by the choice for the common prefix")
+		return
+	}
+
+	fmt.Fprintf(r.out, "Keeping %s by the choice for the common prefix\n", strconv.Quote(kept))
+}
+
+// ask reads the user choice until it is valid, an empty kept file means the group is skipped
+func (r *Reviewer) ask(files []string, suggested string, prefix string) (string, bool, error) {
+	for {
+		fmt.Fprintf(r.out, "Keep [1-%d], s - skip, a<choice> - apply to the common prefix, q - quit", len(files))
+		if suggested != "" {
+			fmt.Fprintf(r.out, " (Enter - keep *)")
+		}
+		fmt.Fprint(r.out, ": ")
+
+		answer, err := r.readLine()
+		if errors.Is(err, io.EOF) {
+			return "", true, nil
+		}
+
+		if err != nil {
+			return "", false, err
+		}
+
+		applyToPrefix := strings.HasPrefix(answer, "a")
+		answer = strings.TrimPrefix(answer, "a")
+
+		var kept string
+		switch {
+		case answer == "q":
+			return "", true, nil
+		case answer == "s":
+			kept = ""
+		case answer == "" && suggested != "":
+			kept = suggested
+		default:
+			number, err := strconv.Atoi(answer)
+			if err != nil || number < 1 || number > len(files) {
+				fmt.Fprintln(r.out, "Unknown choice")
+				continue
+			}
+
+			kept = files[number-1]
+		}
+
+		if applyToPrefix {
+			r.choices[prefix] = newReviewChoice(kept, prefix)
+		}
+
+		return kept, false, nil
+	}
+}
+
+func (r *Reviewer) readLine() (string, error) {
+	line, err := r.in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+func newReviewChoice(kept string, prefix string) reviewChoice {
+	if kept == "" {
+		return reviewChoice{skip: true}
+	}
+
+	return reviewChoice{keptDir: filepath.Dir(strings.TrimPrefix(kept, prefix))}
+}
+
+// apply returns the file to keep, it fails if the choice does not select exactly one file
+func (c reviewChoice) apply(files []string, prefix string) (string, bool) {
+	if c.skip {
+		return "", true
+	}
+
+	var kept []string
+	for _, file := range files {
+		if filepath.Dir(strings.TrimPrefix(file, prefix)) == c.keptDir {
+			kept = append(kept, file)
+		}
+	}
+
+	if len(kept) != 1 {
+		return "", false
+	}
+
+	return kept[0], true
+}
@@ -0,0 +1,113 @@
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewer_Review(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string // Kept files relative to the test directory
+	}{
+		{name: "keep chosen files", input: "2\n1\n", expected: []string{"y/a.txt", "x/b.txt"}},
+		{name: "keep suggested file", input: "\n\n", expected: []string{"x/a.txt", "x/b.txt"}},
+		{name: "skip group", input: "s\n2\n", expected: []string{"y/b.txt"}},
+		{name: "invalid choice is asked again", input: "3\nfoo\n2\n2\n", expected: []string{"y/a.txt", "y/b.txt"}},
+		{name: "apply choice to common prefix", input: "a2\n", expected: []string{"y/a.txt", "y/b.txt"}},
+		{name: "apply skip to common prefix", input: "as\n", expected: nil},
+		{name: "quit", input: "2\nq\n", expected: []string{"y/a.txt"}},
+		{name: "closed input", input: "2\n", expected: []string{"y/a.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir, groups := testutil.NewGroups(t, map[string]string{
+				"x/a.txt": "content a",
+				"y/a.txt": "content a",
+				"x/b.txt": "content b",
+				"y/b.txt": "content b",
+			})
+			groups = sortGroupsByFirstFile(groups)
+			keeper, err := NewKeeper(KeepShortestPath, nil, "")
+			require.NoError(t, err)
+			var out bytes.Buffer
+			reviewer := NewReviewer(strings.NewReader(tt.input), &out, keeper)
+
+			// Act
+			decisions, err := reviewer.Review(groups)
+
+			// Assert
+			require.NoError(t, err)
+			var kept []string
+			for _, decision := range decisions {
+				rel, err := filepath.Rel(dir, decision.Kept)
+				require.NoError(t, err)
+				kept = append(kept, rel)
+			}
+			assert.Equal(t, tt.expected, kept)
+			assert.Contains(t, out.String(), "Group 1 of 2")
+		})
+	}
+}
+
+func TestReviewer_Confirm(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "y\n", expected: true},
+		{input: "yes\n", expected: true},
+		{input: "n\n", expected: false},
+		{input: "\n", expected: false},
+		{input: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		_, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "b.txt": "content"})
+		keeper, err := NewKeeper(KeepOldest, nil, "")
+		require.NoError(t, err)
+		var out bytes.Buffer
+		reviewer := NewReviewer(strings.NewReader(tt.input), &out, keeper)
+
+		confirmed, err := reviewer.Confirm([]Decision{{Group: groups[0], Kept: groups[0].Files()[0]}})
+
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, confirmed, "input %q", tt.input)
+		assert.Contains(t, out.String(), "Process 1 duplicates in 1 groups?")
+	}
+}
+
+// sortGroupsByFirstFile orders the groups by their files, the hash order is not predictable in tests
+func sortGroupsByFirstFile(groups []*checkers.FilesCheckGroup) []*checkers.FilesCheckGroup {
+	slices.SortFunc(groups, func(a, b *checkers.FilesCheckGroup) int {
+		return strings.Compare(a.Files()[0], b.Files()[0])
+	})
+
+	return groups
+}
+
+func TestDeduplicator_DedupeDecisions(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+	deduplicator := newTestDeduplicator(t, NewRemoveAction(), false)
+	kept := filepath.Join(dir, "dir/b.txt")
+
+	// Act
+	stats := deduplicator.DedupeDecisions([]Decision{{Group: groups[0], Kept: kept}})
+
+	// Assert
+	assert.Equal(t, DedupeStats{Groups: 1, Files: 1, ReclaimedBytes: 7}, stats)
+	assert.FileExists(t, kept, "The chosen file should be kept regardless of the keep policy")
+	assert.NoFileExists(t, filepath.Join(dir, "a.txt"))
+}
@@ -0,0 +1,106 @@
+package actions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+)
+
+// ScriptWriter writes a POSIX shell script with the dedupe commands instead of changing the files,
+// so the changes can be reviewed and edited before running the script
+type ScriptWriter struct {
+	out     *bufio.Writer
+	keeper  *Keeper
+	mode    DedupeMode
+	symlink symlinkAction
+}
+
+func NewScriptWriter(out io.Writer, keeper *Keeper, mode DedupeMode, options ActionOptions) (*ScriptWriter, error) {
+	switch mode {
+	case DedupeRemove, DedupeHardlink, DedupeSymlink:
+	default:
+		return nil, fmt.Errorf("%s dedupe mode is not supported by the script", mode)
+	}
+
+	style := options.LinkStyle
+	if style == "" {
+		style = LinkRelative
+	}
+
+	return &ScriptWriter{
+		out:     bufio.NewWriter(out),
+		keeper:  keeper,
+		mode:    mode,
+		symlink: symlinkAction{style: style},
+	}, nil
+}
+
+// Write writes the whole script, the returned stats describe the planned changes
+func (s *ScriptWriter) Write(groups []*checkers.FilesCheckGroup) (DedupeStats, error) {
+	var stats DedupeStats
+	fmt.Fprintf(s.out, "#!/bin/sh\n")
+	fmt.Fprintf(s.out, "# Dedupe script generated at %s (mode: %s, keep: %s)\n", time.Now().Format(time.RFC3339), s.mode, s.keeper.Policy())
+	fmt.Fprintf(s.out, "# Review the commands before running the script, the files could be changed after the scan\n")
+	fmt.Fprintf(s.out, "set -eu\n")
+
+	for i, group := range groups {
+		files := group.Files()
+		fmt.Fprintf(s.out, "\n# Group %d: %s:%s, %d bytes, %d files\n", i+1, group.Algorithm(), group.Hash(), group.Size(), len(files))
+		fmt.Fprintf(s.out, "# Common prefix: %s\n", strconv.Quote(group.CommonPathPrefix()))
+
+		kept, err := s.keeper.Choose(files)
+		if err != nil {
+			fmt.Fprintf(s.out, "# Skipped: %s\n", strconv.Quote(err.Error()))
+			stats.SkippedGroups++
+			continue
+		}
+
+		stats.Groups++
+		fmt.Fprintf(s.out, "# Keep: %s\n", strconv.Quote(kept))
+		for _, file := range files {
+			if file == kept {
+				continue
+			}
+
+			command, err := s.command(file, kept)
+			if err != nil {
+				fmt.Fprintf(s.out, "# Cannot process %s: %s\n", strconv.Quote(file), strconv.Quote(err.Error()))
+				stats.FailedFiles++
+				continue
+			}
+
+			fmt.Fprintln(s.out, command)
+			stats.Files++
+			stats.ReclaimedBytes += group.Size()
+		}
+	}
+
+	return stats, s.out.Flush()
+}
+
+func (s *ScriptWriter) command(duplicate string, kept string) (string, error) {
+	switch s.mode {
+	case DedupeHardlink:
+		return fmt.Sprintf("ln -f -- %s %s", shellQuote(kept), shellQuote(duplicate)), nil
+	case DedupeSymlink:
+		target, err := s.symlink.linkTarget(duplicate, kept)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("ln -sf -- %s %s", shellQuote(target), shellQuote(duplicate)), nil
+	}
+
+	return fmt.Sprintf("rm -f -- %s", shellQuote(duplicate)), nil
+}
+
+// shellQuote quotes the string for POSIX shell: nothing is special inside single quotes,
+// so only the single quotes themselves should be closed, escaped and reopened.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
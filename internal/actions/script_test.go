@@ -0,0 +1,114 @@
+package actions
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "/a/b.txt", expected: `'/a/b.txt'`},
+		{value: "/a/with space.txt", expected: `'/a/with space.txt'`},
+		{value: "/a/it's.txt", expected: `'/a/it'\''s.txt'`},
+		{value: "/a/$(rm -rf x)`y`.txt", expected: "'/a/$(rm -rf x)`y`.txt'"},
+		{value: "/a/new\nline.txt", expected: "'/a/new\nline.txt'"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, shellQuote(tt.value))
+	}
+}
+
+func TestScriptWriter(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("POSIX shell is not found")
+	}
+
+	tests := []struct {
+		name   string
+		mode   DedupeMode
+		assert func(t *testing.T, kept string, duplicate string)
+	}{
+		{
+			name: "remove",
+			mode: DedupeRemove,
+			assert: func(t *testing.T, kept string, duplicate string) {
+				assert.NoFileExists(t, duplicate)
+			},
+		},
+		{
+			name: "hardlink",
+			mode: DedupeHardlink,
+			assert: func(t *testing.T, kept string, duplicate string) {
+				keptInfo, err := os.Stat(kept)
+				require.NoError(t, err)
+				info, err := os.Stat(duplicate)
+				require.NoError(t, err)
+				assert.True(t, os.SameFile(keptInfo, info))
+			},
+		},
+		{
+			name: "symlink",
+			mode: DedupeSymlink,
+			assert: func(t *testing.T, kept string, duplicate string) {
+				target, err := os.Readlink(duplicate)
+				require.NoError(t, err)
+				assert.Equal(t, "../a 'quoted'.txt", target)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir, groups := testutil.NewGroups(t, map[string]string{
+				"a 'quoted'.txt":       "content",
+				"dir/new\nline $x.txt": "content",
+			})
+			keeper, err := NewKeeper(KeepShortestPath, nil, "")
+			require.NoError(t, err)
+			var script bytes.Buffer
+			writer, err := NewScriptWriter(&script, keeper, tt.mode, ActionOptions{})
+			require.NoError(t, err)
+
+			// Act
+			stats, err := writer.Write(groups)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, DedupeStats{Groups: 1, Files: 1, ReclaimedBytes: 7}, stats)
+			assert.Contains(t, script.String(), "# Group 1: sha256:"+groups[0].Hash()+", 7 bytes, 2 files\n")
+
+			kept := filepath.Join(dir, "a 'quoted'.txt")
+			duplicate := filepath.Join(dir, "dir/new\nline $x.txt")
+			assert.FileExists(t, duplicate, "The script writer should not change files")
+
+			output, err := exec.Command(sh, "-c", script.String()).CombinedOutput()
+			require.NoError(t, err, string(output))
+
+			tt.assert(t, kept, duplicate)
+			content, err := os.ReadFile(kept)
+			require.NoError(t, err)
+			assert.Equal(t, "content", string(content))
+		})
+	}
+}
+
+func TestNewScriptWriter_UnsupportedMode(t *testing.T) {
+	keeper, err := NewKeeper(KeepOldest, nil, "")
+	require.NoError(t, err)
+
+	_, err = NewScriptWriter(&bytes.Buffer{}, keeper, DedupeReflink, ActionOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrNotUndoable means the journal entry describes a change which cannot be reversed
+var ErrNotUndoable = errors.New("change cannot be undone")
+
+// UndoStats is the result of the journal undo
+type UndoStats struct {
+	Restored    int // Reversed changes
+	NotUndoable int // Changes which cannot be reversed, e.g. removed files
+	Failed      int // Changes which could be reversed, but an error happened
+}
+
+// Undoer reverses the changes recorded in the journal
+type Undoer struct {
+	logger zerolog.Logger
+}
+
+func NewUndoer(logger zerolog.Logger) *Undoer {
+	return &Undoer{logger: logger}
+}
+
+// Undo reverses the entries starting from the latest one
+func (u *Undoer) Undo(entries []JournalEntry) UndoStats {
+	var stats UndoStats
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		err := u.undoEntry(entry)
+		switch {
+		case errors.Is(err, ErrNotUndoable):
+			u.logger.Warn().
+				Str("path", entry.Path).
+				Str("action", entry.Action).
+				Msgf("Cannot undo: %v", err)
+			stats.NotUndoable++
+		case err != nil:
+			u.logger.Error().
+				Str("path", entry.Path).
+				Str("action", entry.Action).
+				Msgf("Undo failed: %v", err)
+			stats.Failed++
+		default:
+			u.logger.Info().
+				Str("path", entry.Path).
+				Str("action", entry.Action).
+				Msg("Change undone")
+			stats.Restored++
+		}
+	}
+
+	return stats
+}
+
+func (u *Undoer) undoEntry(entry JournalEntry) error {
+	switch DedupeMode(entry.Action) {
+	case DedupeRemove:
+		return fmt.Errorf("%w: removed file content is lost", ErrNotUndoable)
+	case DedupeReflink:
+		return fmt.Errorf("%w: reflinked file is an independent copy already", ErrNotUndoable)
+	case DedupeQuarantine:
+		return undoQuarantine(entry)
+	case DedupeHardlink:
+		return undoHardlink(entry)
+	case DedupeSymlink:
+		return undoSymlink(entry)
+	}
+
+	return fmt.Errorf("%w: unknown action %q", ErrNotUndoable, entry.Action)
+}
+
+// undoQuarantine moves the quarantined file back to its original path
+func undoQuarantine(entry JournalEntry) error {
+	if _, err := os.Lstat(entry.Path); err == nil {
+		return fmt.Errorf("%w: original path is taken", os.ErrExist)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := moveFile(entry.Target, entry.Path); err != nil {
+		return err
+	}
+
+	return restoreAttributes(entry.Path, entry)
+}
+
+// undoHardlink replaces the hard link with an independent copy
+func undoHardlink(entry JournalEntry) error {
+	info, err := os.Lstat(entry.Path)
+	if err != nil {
+		return err
+	}
+
+	targetInfo, err := os.Stat(entry.Target)
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(info, targetInfo) {
+		return fmt.Errorf("%w: not a hard link to %s", ErrFileChanged, entry.Target)
+	}
+
+	return replaceWithCopy(entry)
+}
+
+// undoSymlink replaces the symbolic link with an independent copy of its target
+func undoSymlink(entry JournalEntry) error {
+	info, err := os.Lstat(entry.Path)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("%w: not a symbolic link", ErrFileChanged)
+	}
+
+	linkedInfo, err := os.Stat(entry.Path)
+	if err != nil {
+		return err
+	}
+
+	targetInfo, err := os.Stat(entry.Target)
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(linkedInfo, targetInfo) {
+		return fmt.Errorf("%w: not a symbolic link to %s", ErrFileChanged, entry.Target)
+	}
+
+	return replaceWithCopy(entry)
+}
+
+// replaceWithCopy replaces the link with a copy of the linked content
+func replaceWithCopy(entry JournalEntry) error {
+	return replaceAtomically(entry.Path, func(tempPath string) error {
+		if err := copyFile(entry.Path, tempPath); err != nil {
+			if !errors.Is(err, os.ErrExist) {
+				os.Remove(tempPath)
+			}
+
+			return err
+		}
+
+		if err := restoreAttributes(tempPath, entry); err != nil {
+			os.Remove(tempPath)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// restoreAttributes sets the mode and the modification time the file had before the change
+func restoreAttributes(path string, entry JournalEntry) error {
+	if err := os.Chmod(path, entry.Mode.Perm()); err != nil {
+		return err
+	}
+
+	return os.Chtimes(path, entry.ModTime, entry.ModTime)
+}
@@ -0,0 +1,179 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_RecordAndRead(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "journal.ndjson")
+	entries := []JournalEntry{
+		{Path: "/a/b.txt", Action: "remove", Target: "/a/a.txt", Hash: "h1", Size: 7, Mode: 0640},
+		{Path: "/a/c.txt", Action: "hardlink", Target: "/a/a.txt", Hash: "h1", Size: 7, Mode: 0644},
+	}
+
+	// Act
+	journal, err := OpenJournal(path)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.NoError(t, journal.Record(entry))
+	}
+	require.NoError(t, journal.Close())
+
+	read, err := ReadJournal(path)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, entries, read)
+}
+
+func TestReadJournal_Statuses(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "journal.ndjson")
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	applied := JournalEntry{Time: start, Path: "/a/b.txt", Action: "hardlink", Target: "/a/a.txt"}
+	failed := JournalEntry{Time: start.Add(time.Second), Path: "/a/c.txt", Action: "hardlink", Target: "/a/a.txt"}
+	interrupted := JournalEntry{Time: start.Add(2 * time.Second), Path: "/a/d.txt", Action: "hardlink", Target: "/a/a.txt"}
+
+	journal, err := OpenJournal(path)
+	require.NoError(t, err)
+	for _, entry := range []JournalEntry{applied, failed, interrupted} {
+		entry.Status = JournalPending
+		require.NoError(t, journal.Record(entry))
+	}
+	applied.Status = JournalApplied
+	require.NoError(t, journal.Record(applied))
+	failed.Status = JournalFailed
+	require.NoError(t, journal.Record(failed))
+	require.NoError(t, journal.Close())
+
+	// Act
+	read, err := ReadJournal(path)
+
+	// Assert
+	require.NoError(t, err)
+	interrupted.Status = JournalPending
+	assert.Equal(t, []JournalEntry{applied, interrupted}, read, "The failed change should be dropped, the interrupted one kept")
+}
+
+func TestDefaultJournalPath(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, "gofileschecker-journal-20250102-030405.ndjson", DefaultJournalPath(now))
+	assert.NotEqual(t, DefaultJournalPath(now), DefaultJournalPath(now.Add(time.Second)))
+}
+
+func TestUndoer_Undo(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   func(t *testing.T) Action
+		expected UndoStats
+		restored bool // The duplicate should become an independent file with the original content
+	}{
+		{
+			name:     "remove",
+			action:   func(t *testing.T) Action { return NewRemoveAction() },
+			expected: UndoStats{NotUndoable: 1},
+		},
+		{
+			name:     "hardlink",
+			action:   func(t *testing.T) Action { return NewHardlinkAction() },
+			expected: UndoStats{Restored: 1},
+			restored: true,
+		},
+		{
+			name:     "symlink",
+			action:   func(t *testing.T) Action { return NewSymlinkAction(LinkRelative) },
+			expected: UndoStats{Restored: 1},
+			restored: true,
+		},
+		{
+			name: "quarantine",
+			action: func(t *testing.T) Action {
+				action, err := NewQuarantineAction(t.TempDir())
+				require.NoError(t, err)
+				return action
+			},
+			expected: UndoStats{Restored: 1},
+			restored: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "dir/b.txt": "content"})
+			kept := filepath.Join(dir, "a.txt")
+			duplicate := filepath.Join(dir, "dir/b.txt")
+			modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			require.NoError(t, os.Chmod(duplicate, 0600))
+			require.NoError(t, os.Chtimes(duplicate, modTime, modTime))
+
+			journalPath := filepath.Join(t.TempDir(), "journal.ndjson")
+			journal, err := OpenJournal(journalPath)
+			require.NoError(t, err)
+
+			keeper, err := NewKeeper(KeepShortestPath, nil, "")
+			require.NoError(t, err)
+			stats := NewDeduplicator(zerolog.Nop(), keeper, tt.action(t), journal, false).Dedupe(groups)
+			require.Equal(t, 1, stats.Files)
+			require.NoError(t, journal.Close())
+
+			entries, err := ReadJournal(journalPath)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+
+			// Act
+			undoStats := NewUndoer(zerolog.Nop()).Undo(entries)
+
+			// Assert
+			assert.Equal(t, tt.expected, undoStats)
+			if !tt.restored {
+				return
+			}
+
+			content, err := os.ReadFile(duplicate)
+			require.NoError(t, err)
+			assert.Equal(t, "content", string(content))
+
+			info, err := os.Lstat(duplicate)
+			require.NoError(t, err)
+			keptInfo, err := os.Stat(kept)
+			require.NoError(t, err)
+			assert.True(t, info.Mode().IsRegular(), "The duplicate should not be a link")
+			assert.False(t, os.SameFile(info, keptInfo), "The duplicate should be an independent copy")
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+			assert.True(t, modTime.Equal(info.ModTime()))
+
+			entriesInDir, err := os.ReadDir(filepath.Join(dir, "dir"))
+			require.NoError(t, err)
+			assert.Len(t, entriesInDir, 1, "No temporary files should be left")
+		})
+	}
+}
+
+func TestUndoer_ChangedFile(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := filepath.Join(dir, "b.txt")
+	require.NoError(t, os.WriteFile(path, []byte("new content"), 0644))
+	entries := []JournalEntry{{Path: path, Action: "hardlink", Target: filepath.Join(dir, "a.txt"), Mode: 0644}}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("content"), 0644))
+
+	// Act
+	stats := NewUndoer(zerolog.Nop()).Undo(entries)
+
+	// Assert
+	assert.Equal(t, UndoStats{Failed: 1}, stats)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new content", string(content), "The changed file should not be touched")
+}
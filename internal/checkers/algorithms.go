@@ -0,0 +1,71 @@
+package checkers
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"hash/fnv"
+	"slices"
+
+	"github.com/pryazhnikov/gofileschecker/internal/xxh64"
+)
+
+// HashAlgorithm is a name of the algorithm used to calculate file hashes
+type HashAlgorithm string
+
+const (
+	HashSHA256 HashAlgorithm = "sha256"
+	HashSHA512 HashAlgorithm = "sha512"
+	HashSHA1   HashAlgorithm = "sha1"
+	HashMD5    HashAlgorithm = "md5"
+	HashCRC64  HashAlgorithm = "crc64"
+	HashFNV128 HashAlgorithm = "fnv-128"
+	HashXXH64  HashAlgorithm = "xxh64" // Fast non-cryptographic hash
+
+	DefaultHashAlgorithm = HashSHA256
+)
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+var hashAlgorithms = map[HashAlgorithm]func() hash.Hash{
+	HashSHA256: sha256.New,
+	HashSHA512: sha512.New,
+	HashSHA1:   sha1.New,
+	HashMD5:    md5.New,
+	HashCRC64:  func() hash.Hash { return crc64.New(crc64Table) },
+	HashFNV128: fnv.New128,
+	HashXXH64:  func() hash.Hash { return xxh64.New() },
+}
+
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	algorithm := HashAlgorithm(name)
+	if _, ok := hashAlgorithms[algorithm]; !ok {
+		return "", fmt.Errorf("unknown hash algorithm: %q", name)
+	}
+
+	return algorithm, nil
+}
+
+// HashAlgorithmNames returns the names of all supported algorithms in lexical order
+func HashAlgorithmNames() []string {
+	names := make([]string, 0, len(hashAlgorithms))
+	for algorithm := range hashAlgorithms {
+		names = append(names, string(algorithm))
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+func (a HashAlgorithm) New() (hash.Hash, error) {
+	newHash, ok := hashAlgorithms[a]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash algorithm: %q", string(a))
+	}
+
+	return newHash(), nil
+}
@@ -0,0 +1,94 @@
+package checkers
+
+import (
+	"hash"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil/filestest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHashAlgorithm(t *testing.T) {
+	for _, name := range HashAlgorithmNames() {
+		algorithm, err := ParseHashAlgorithm(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, string(algorithm))
+		hasher, err := algorithm.New()
+		require.NoError(t, err)
+		assert.NotNil(t, hasher, "Hash for %s should be created", name)
+	}
+
+	_, err := ParseHashAlgorithm("sha3")
+	assert.Error(t, err)
+
+	_, err = HashAlgorithm("sha3").New()
+	assert.Error(t, err)
+}
+
+func TestFileChecker_HashAlgorithms(t *testing.T) {
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{"a.txt": "content", "b.txt": "content", "c.txt": "another"})
+
+	hashes := make(map[string]string)
+	for _, name := range HashAlgorithmNames() {
+		t.Run(name, func(t *testing.T) {
+			algorithm := HashAlgorithm(name)
+			fc, err := NewFileChecker(Options{HashAlgorithm: algorithm})
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, fc.Algorithm())
+
+			filestest.CheckFiles(t, fc, paths)
+
+			groups := fc.GetDuplicatedFileGroups()
+			require.Len(t, groups, 1)
+			assert.Equal(t, []string{paths["a.txt"], paths["b.txt"]}, groups[0].Files())
+			assert.Equal(t, algorithm, groups[0].Algorithm(), "Group should keep the algorithm name")
+			hashes[name] = groups[0].Hash()
+		})
+	}
+
+	assert.Equal(t, "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73", hashes["sha256"])
+	assert.Len(t, hashes["xxh64"], 16, "xxh64 hash should be 8 bytes long")
+}
+
+func TestNewFileChecker_UnknownAlgorithm(t *testing.T) {
+	_, err := NewFileChecker(Options{HashAlgorithm: "sha3"})
+	assert.Error(t, err)
+}
+
+func TestNewFileChecker_DefaultAlgorithm(t *testing.T) {
+	fc, err := NewFileChecker(Options{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultHashAlgorithm, fc.Algorithm())
+}
+
+// constantHash returns the same sum for any content, it simulates the hash collisions
+type constantHash struct{}
+
+func (constantHash) Write(p []byte) (int, error) { return len(p), nil }
+func (constantHash) Sum(b []byte) []byte         { return append(b, 0x42) }
+func (constantHash) Reset()                      {}
+func (constantHash) Size() int                   { return 1 }
+func (constantHash) BlockSize() int              { return 1 }
+
+func TestFileChecker_HashCollisionOfDifferentSizes(t *testing.T) {
+	// Arrange
+	const algorithm HashAlgorithm = "constant"
+	hashAlgorithms[algorithm] = func() hash.Hash { return constantHash{} }
+	t.Cleanup(func() { delete(hashAlgorithms, algorithm) })
+
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{"a.txt": "one", "b.txt": "two", "c.txt": "three", "d.txt": "seven"})
+	fc, err := NewFileChecker(Options{HashAlgorithm: algorithm, PartialBlockSize: 0})
+	require.NoError(t, err)
+
+	// Act
+	filestest.CheckFiles(t, fc, paths)
+
+	// Assert
+	groups := fc.GetDuplicatedFileGroups()
+	require.Len(t, groups, 2, "The files of different sizes should not be merged")
+	assert.Equal(t, int64(3), groups[0].Size())
+	assert.Equal(t, []string{paths["a.txt"], paths["b.txt"]}, groups[0].Files())
+	assert.Equal(t, int64(5), groups[1].Size())
+	assert.Equal(t, []string{paths["c.txt"], paths["d.txt"]}, groups[1].Files())
+}
@@ -1,9 +1,8 @@
 package checkers
 
 import (
-	"crypto/sha256"
+	"cmp"
 	"fmt"
-	"io"
 	"os"
 	"slices"
 	"strings"
@@ -13,9 +12,11 @@ import (
 )
 
 type FilesCheckGroup struct {
-	mu    sync.RWMutex // Protects access to files slice
-	hash  string       // common hash for all files in the group
-	files []string
+	mu        sync.RWMutex  // Protects access to files slice
+	algorithm HashAlgorithm // algorithm used to calculate the hash
+	hash      string        // common hash for all files in the group
+	size      int64         // common size for all files in the group
+	files     []string
 }
 
 func (fcg *FilesCheckGroup) HasFile(file string) bool {
@@ -42,9 +43,11 @@ func (fcg *FilesCheckGroup) Files() []string {
 	fcg.mu.RLock()
 	defer fcg.mu.RUnlock()
 
-	// Return a copy of the files slice to prevent external modifications
+	// Return a copy of the files slice to prevent external modifications.
+	// Files are checked in parallel, so the copy is sorted to make the result order stable.
 	result := make([]string, len(fcg.files))
 	copy(result, fcg.files)
+	slices.Sort(result)
 	return result
 }
 
@@ -102,69 +105,334 @@ func (fcg *FilesCheckGroup) Hash() string {
 	return fcg.hash
 }
 
-func newFilesCheckGroup(hash string, file string) *FilesCheckGroup {
+func (fcg *FilesCheckGroup) Algorithm() HashAlgorithm {
+	return fcg.algorithm
+}
+
+func (fcg *FilesCheckGroup) Size() int64 {
+	return fcg.size
+}
+
+func newFilesCheckGroup(algorithm HashAlgorithm, hash string, size int64, file string) *FilesCheckGroup {
 	return &FilesCheckGroup{
-		hash:  hash,
-		files: []string{file},
-		mu:    sync.RWMutex{},
+		algorithm: algorithm,
+		hash:      hash,
+		size:      size,
+		files:     []string{file},
+		mu:        sync.RWMutex{},
 	}
 }
 
+// fileState is a stage of the file processing
+type fileState int
+
+const (
+	fileStateRegistered    fileState = iota // Only the file size is known
+	fileStatePartialHashed                  // The hash of the file head and tail is calculated
+	fileStateHashed                         // The file content hash is calculated
+	fileStateFailed                         // The file content cannot be read
+)
+
+type registeredFile struct {
+	size  int64
+	state fileState
+}
+
+// isFinished checks if the file is not checked anymore
+func (f *registeredFile) isFinished() bool {
+	return f.state == fileStateHashed || f.state == fileStateFailed
+}
+
+// hashKey identifies files which can be duplicates by their size and hash.
+// The size is a part of the key, so the colliding hashes of different sizes are not merged.
+type hashKey struct {
+	size int64
+	hash string
+}
+
+// CheckStats shows how much work was avoided by the size-based and the partial hash filtering
+type CheckStats struct {
+	RegisteredFiles    int   // Files passed to Add
+	UniqueSizeFiles    int   // Files which were not read because no other file has the same size
+	PartialHashedFiles int   // Files which head and tail were hashed
+	PartialHashedBytes int64 // Bytes read to calculate partial hashes
+	HashedFiles        int   // Files which content was hashed
+	HashedBytes        int64 // Bytes read to calculate hashes
+	SavedBytes         int64 // Bytes which were not read thanks to the filtering
+}
+
+type Options struct {
+	PartialBlockSize int64         // Size of the file head and tail used for the partial hash, 0 disables the partial hash
+	HashAlgorithm    HashAlgorithm // Algorithm of both partial and full hashes, DefaultHashAlgorithm is used if empty
+	// OnGroup is called for every duplicated group as soon as all files of its size are checked,
+	// so the group is complete. It is called from the Check callers, possibly from multiple goroutines.
+	OnGroup func(group *FilesCheckGroup)
+}
+
+// FileChecker finds duplicated files in multiple phases: the files are grouped by size first,
+// then the files with non-unique size are grouped by the partial hash of their head and tail,
+// and only the files with non-unique partial hash are hashed completely.
 type FileChecker struct {
-	fileGroups     map[string]*FilesCheckGroup
-	skipEmptyFiles bool
-	mu             sync.RWMutex
+	files            map[string]*registeredFile   // All registered files by their paths
+	sizeGroups       map[int64][]string           // Paths of registered files by their sizes
+	partialGroups    map[hashKey][]string         // Paths of partially hashed files by their sizes and partial hashes
+	fileGroups       map[hashKey]*FilesCheckGroup // Groups of fully hashed files by their sizes and hashes
+	sizeFileGroups   map[int64][]*FilesCheckGroup // Groups not passed to onGroup yet by their sizes
+	unfinished       map[int64]int                // Numbers of files which should be checked further by their sizes
+	onGroup          func(group *FilesCheckGroup)
+	stats            CheckStats
+	partialBlockSize int64
+	algorithm        HashAlgorithm
+	mu               sync.RWMutex
 }
 
 // Ensure FileChecker implements scanner.FileChecker interface
 var _ scanner.FileChecker = (*FileChecker)(nil)
 
-func NewFileChecker(skipEmptyFiles bool) *FileChecker {
+func NewFileChecker(options Options) (*FileChecker, error) {
+	algorithm := options.HashAlgorithm
+	if algorithm == "" {
+		algorithm = DefaultHashAlgorithm
+	}
+
+	if _, err := ParseHashAlgorithm(string(algorithm)); err != nil {
+		return nil, err
+	}
+
 	return &FileChecker{
-		skipEmptyFiles: skipEmptyFiles,
-		fileGroups:     make(map[string]*FilesCheckGroup),
+		partialBlockSize: max(options.PartialBlockSize, 0),
+		algorithm:        algorithm,
+		files:            make(map[string]*registeredFile),
+		sizeGroups:       make(map[int64][]string),
+		partialGroups:    make(map[hashKey][]string),
+		fileGroups:       make(map[hashKey]*FilesCheckGroup),
+		sizeFileGroups:   make(map[int64][]*FilesCheckGroup),
+		unfinished:       make(map[int64]int),
+		onGroup:          options.OnGroup,
+	}, nil
+}
+
+func (fc *FileChecker) Add(path string, size int64) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.addLocked(path, size)
+	return nil
+}
+
+func (fc *FileChecker) addLocked(path string, size int64) {
+	if _, ok := fc.files[path]; ok {
+		return
 	}
+
+	fc.files[path] = &registeredFile{size: size, state: fileStateRegistered}
+	fc.sizeGroups[size] = append(fc.sizeGroups[size], path)
+	fc.unfinished[size]++
+	fc.stats.RegisteredFiles++
 }
 
-func (fc *FileChecker) Check(path string) (string, error) {
-	if fc.skipEmptyFiles {
-		fileInfo, err := os.Stat(path)
-		if err != nil {
-			return "", fmt.Errorf("failed to get file info: %w", err)
-		}
+// Candidates returns the files which should be processed by the next phase:
+// not yet hashed files which have at least one other file of the same size,
+// and partially hashed files which have at least one other file with the same partial hash.
+func (fc *FileChecker) Candidates() []string {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	var result []string
+	for _, paths := range fc.sizeGroups {
+		result = fc.appendCandidates(result, paths, fileStateRegistered)
+	}
+
+	for _, paths := range fc.partialGroups {
+		result = fc.appendCandidates(result, paths, fileStatePartialHashed)
+	}
+
+	slices.Sort(result)
+	return result
+}
 
-		// Idea: all empty files have the same hash and will be combined in the same group
-		if fileInfo.Size() == 0 {
-			return "", fmt.Errorf("skipping empty file")
+func (fc *FileChecker) appendCandidates(candidates []string, paths []string, state fileState) []string {
+	if len(paths) < 2 {
+		return candidates
+	}
+
+	for _, path := range paths {
+		if fc.files[path].state == state {
+			candidates = append(candidates, path)
 		}
 	}
 
-	file, err := os.Open(path)
+	return candidates
+}
+
+func (fc *FileChecker) Algorithm() HashAlgorithm {
+	return fc.algorithm
+}
+
+// Check moves the file to the next processing phase and returns the calculated (partial or full) hash
+func (fc *FileChecker) Check(path string) (string, error) {
+	if err := fc.ensureRegistered(path); err != nil {
+		return "", err
+	}
+
+	fc.mu.RLock()
+	file := *fc.files[path]
+	fc.mu.RUnlock()
+
+	// The partial hash of a small file reads the whole file anyway
+	if file.state == fileStateRegistered && fc.partialBlockSize > 0 && file.size > 2*fc.partialBlockSize {
+		return fc.checkPartial(path, file.size)
+	}
+
+	return fc.checkFull(path)
+}
+
+func (fc *FileChecker) checkPartial(path string, size int64) (string, error) {
+	hash, readBytes, err := calculatePartialHash(path, size, fc.partialBlockSize, fc.algorithm)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		fc.fail(path)
+		return "", err
 	}
-	defer file.Close()
 
-	hasher := sha256.New()
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", fmt.Errorf("failed to calculate hash: %w", err)
+	fc.mu.Lock()
+	file := fc.files[path]
+	file.state = fileStatePartialHashed
+	fc.stats.PartialHashedFiles++
+	fc.stats.PartialHashedBytes += readBytes
+
+	// The file with a unique partial hash is not checked further unless another file gets the same hash
+	key := hashKey{size: size, hash: hash}
+	fc.partialGroups[key] = append(fc.partialGroups[key], path)
+	switch len(fc.partialGroups[key]) {
+	case 1:
+		fc.unfinished[size]--
+	case 2:
+		fc.unfinished[size]++ // The first file of the partial group should be hashed completely now
 	}
 
-	hash := fmt.Sprintf("%x", hasher.Sum(nil))
+	groups := fc.completedGroupsLocked(size)
+	fc.mu.Unlock()
+
+	fc.notify(groups)
+	return hash, nil
+}
+
+func (fc *FileChecker) checkFull(path string) (string, error) {
+	hash, readBytes, err := calculateHash(path, fc.algorithm)
+	if err != nil {
+		fc.fail(path)
+		return "", err
+	}
 
 	fc.mu.Lock()
-	defer fc.mu.Unlock()
+	file := fc.files[path]
+	if !file.isFinished() {
+		fc.unfinished[file.size]--
+	}
 
-	hfr, ok := fc.fileGroups[hash]
+	file.state = fileStateHashed
+	fc.stats.HashedFiles++
+	fc.stats.HashedBytes += readBytes
+
+	key := hashKey{size: file.size, hash: hash}
+	hfr, ok := fc.fileGroups[key]
 	if ok {
 		hfr.addFile(path)
 	} else {
-		fc.fileGroups[hash] = newFilesCheckGroup(hash, path)
+		hfr = newFilesCheckGroup(fc.algorithm, hash, key.size, path)
+		fc.fileGroups[key] = hfr
+		fc.sizeFileGroups[key.size] = append(fc.sizeFileGroups[key.size], hfr)
 	}
 
+	groups := fc.completedGroupsLocked(file.size)
+	fc.mu.Unlock()
+
+	fc.notify(groups)
 	return hash, nil
 }
 
+// ensureRegistered allows to check the files which were not passed to Add
+func (fc *FileChecker) ensureRegistered(path string) error {
+	fc.mu.RLock()
+	_, ok := fc.files[path]
+	fc.mu.RUnlock()
+	if ok {
+		return nil
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	if err := fc.Add(path, fileInfo.Size()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// fail marks the file as failed, it is not checked anymore
+func (fc *FileChecker) fail(path string) {
+	fc.mu.Lock()
+	file := fc.files[path]
+	if !file.isFinished() {
+		fc.unfinished[file.size]--
+	}
+
+	file.state = fileStateFailed
+	groups := fc.completedGroupsLocked(file.size)
+	fc.mu.Unlock()
+
+	fc.notify(groups)
+}
+
+// completedGroupsLocked returns the duplicated groups of the size once all files of the size are checked.
+// Every group is returned only once.
+func (fc *FileChecker) completedGroupsLocked(size int64) []*FilesCheckGroup {
+	if fc.onGroup == nil || fc.unfinished[size] > 0 {
+		return nil
+	}
+
+	var result []*FilesCheckGroup
+	for _, group := range fc.sizeFileGroups[size] {
+		if group.HasMultipleFiles() {
+			result = append(result, group)
+		}
+	}
+
+	delete(fc.sizeFileGroups, size)
+	return result
+}
+
+// notify passes the completed groups to the callback, it should be called without the lock
+func (fc *FileChecker) notify(groups []*FilesCheckGroup) {
+	for _, group := range groups {
+		fc.onGroup(group)
+	}
+}
+
+func (fc *FileChecker) Stats() CheckStats {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	stats := fc.stats
+	for size, paths := range fc.sizeGroups {
+		if len(paths) == 1 {
+			stats.UniqueSizeFiles++
+			stats.SavedBytes += size
+		}
+	}
+
+	for key, paths := range fc.partialGroups {
+		if len(paths) == 1 {
+			stats.SavedBytes += key.size - 2*fc.partialBlockSize
+		}
+	}
+
+	return stats
+}
+
 func (fc *FileChecker) GetDuplicatedFileGroups() []*FilesCheckGroup {
 	fc.mu.RLock()
 	defer fc.mu.RUnlock()
@@ -176,5 +444,10 @@ func (fc *FileChecker) GetDuplicatedFileGroups() []*FilesCheckGroup {
 		}
 	}
 
+	// Map iteration order is random, sorting groups to get the same output for the same files
+	slices.SortFunc(result, func(a, b *FilesCheckGroup) int {
+		return cmp.Or(strings.Compare(a.Hash(), b.Hash()), cmp.Compare(a.Size(), b.Size()))
+	})
+
 	return result
 }
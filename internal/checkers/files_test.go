@@ -1,22 +1,29 @@
 package checkers
 
 import (
+	"os"
+	"sync"
 	"testing"
 
+	"github.com/pryazhnikov/gofileschecker/internal/testutil/filestest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewFilesCheckGroup(t *testing.T) {
 	// Arrange
 	expectedHash := "test-hash-123"
 	expectedFile := "initial-file.txt"
+	expectedSize := int64(42)
 
 	// Act
-	fcg := newFilesCheckGroup(expectedHash, expectedFile)
+	fcg := newFilesCheckGroup(HashSHA256, expectedHash, expectedSize, expectedFile)
 
 	// Assert
 	assert.NotNil(t, fcg, "newFilesCheckGroup should not return nil")
 	assert.Equal(t, expectedHash, fcg.Hash(), "Hash should match the input value")
+	assert.Equal(t, expectedSize, fcg.Size(), "Size should match the input value")
+	assert.Equal(t, HashSHA256, fcg.Algorithm(), "Algorithm should match the input value")
 
 	files := fcg.Files()
 	assert.Len(t, files, 1, "Should have exactly one file")
@@ -29,7 +36,7 @@ func TestFilesCheckGroup_AddFile(t *testing.T) {
 	// Arrange
 	initialHash := "test-hash"
 	initialFile := "file1.txt"
-	fcg := newFilesCheckGroup(initialHash, initialFile)
+	fcg := newFilesCheckGroup(HashSHA256, initialHash, 0, initialFile)
 	assert.False(t, fcg.HasMultipleFiles(), "HasMultipleFiles should be false initially")
 
 	// Act
@@ -50,7 +57,7 @@ func TestFilesCheckGroup_HasFile(t *testing.T) {
 	// Arrange
 	initialHash := "test-hash"
 	initialFile := "file1.txt"
-	fcg := newFilesCheckGroup(initialHash, initialFile)
+	fcg := newFilesCheckGroup(HashSHA256, initialHash, 0, initialFile)
 
 	// Act & Assert
 	assert.True(t, fcg.HasFile(initialFile), "Should return true for existing file")
@@ -68,7 +75,7 @@ func TestFilesCheckGroup_AddTheSameFile(t *testing.T) {
 	// Arrange
 	initialHash := "test-hash"
 	initialFile := "file1.txt"
-	fcg := newFilesCheckGroup(initialHash, initialFile)
+	fcg := newFilesCheckGroup(HashSHA256, initialHash, 0, initialFile)
 	assert.False(t, fcg.HasMultipleFiles(), "HasMultipleFiles should be false initially")
 	assert.Equal(t, 1, fcg.FilesCount(), "The only file is expected")
 
@@ -88,7 +95,7 @@ func TestFilesCheckGroup_FilesShouldBeImmutable(t *testing.T) {
 	assert.NotEqual(t, initialFile, modifedFile, "Precondition failed: files should be different")
 
 	// Arrange
-	fcg := newFilesCheckGroup(initialHash, initialFile)
+	fcg := newFilesCheckGroup(HashSHA256, initialHash, 0, initialFile)
 
 	assert.True(t, fcg.HasFile(initialFile), "Group should contain the initial file")
 	assert.False(t, fcg.HasFile(modifedFile), "Group should not contain the modified file")
@@ -147,7 +154,7 @@ func TestFilesCheckGroup_CommonPathPrefix(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a FilesCheckGroup with a dummy hash
-			fcg := newFilesCheckGroup("dummy-hash", "")
+			fcg := newFilesCheckGroup(HashSHA256, "dummy-hash", 0, "")
 			fcg.files = tt.files // Override the files directly for testing
 
 			result := fcg.CommonPathPrefix()
@@ -155,3 +162,213 @@ func TestFilesCheckGroup_CommonPathPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestFileChecker_HashesOnlySameSizeFiles(t *testing.T) {
+	// Arrange
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{
+		"a.txt":      "same content",
+		"b.txt":      "same content",
+		"c.txt":      "diff content", // The same size, but different content
+		"unique.txt": "unique size content",
+	})
+	fc, err := NewFileChecker(Options{})
+	require.NoError(t, err)
+
+	// Act
+	filestest.CheckFiles(t, fc, paths)
+
+	// Assert
+	groups := fc.GetDuplicatedFileGroups()
+	require.Len(t, groups, 1, "Only one duplicated group is expected")
+	assert.Equal(t, []string{paths["a.txt"], paths["b.txt"]}, groups[0].Files())
+
+	stats := fc.Stats()
+	assert.Equal(t, 4, stats.RegisteredFiles)
+	assert.Equal(t, 1, stats.UniqueSizeFiles)
+	assert.Equal(t, 3, stats.HashedFiles, "The unique size file should not be hashed")
+	assert.Equal(t, int64(3*len("same content")), stats.HashedBytes)
+}
+
+func TestFileChecker_CandidatesAreReturnedOnce(t *testing.T) {
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{"a.txt": "content", "b.txt": "content"})
+	fc, err := NewFileChecker(Options{})
+	require.NoError(t, err)
+
+	require.NoError(t, fc.Add(paths["a.txt"], 7))
+	assert.Empty(t, fc.Candidates(), "A single file of its size is not a candidate")
+
+	require.NoError(t, fc.Add(paths["b.txt"], 7))
+	assert.Equal(t, []string{paths["a.txt"], paths["b.txt"]}, fc.Candidates())
+
+	_, err = fc.Check(paths["a.txt"])
+	require.NoError(t, err)
+	assert.Equal(t, []string{paths["b.txt"]}, fc.Candidates(), "Checked files should not be returned")
+
+	// Failed files should not be returned either, otherwise the check never ends
+	require.NoError(t, os.Remove(paths["b.txt"]))
+	_, err = fc.Check(paths["b.txt"])
+	require.Error(t, err)
+	assert.Empty(t, fc.Candidates())
+}
+
+func TestFileChecker_PartialHash(t *testing.T) {
+	size := int64(len("head-middle1-tail"))
+	tests := []struct {
+		name               string
+		blockSize          int64
+		partialHashedFiles int
+		partialHashedBytes int64
+		hashedFiles        int
+		hashedBytes        int64
+		savedBytes         int64
+	}{
+		{
+			name:               "partial hash enabled",
+			blockSize:          4,
+			partialHashedFiles: 4,
+			partialHashedBytes: 4 * 2 * 4,
+			hashedFiles:        5, // d.txt should not be hashed completely
+			hashedBytes:        3*size + 2*7,
+			savedBytes:         size - 2*4,
+		},
+		{
+			name:        "partial hash disabled",
+			blockSize:   0,
+			hashedFiles: 6,
+			hashedBytes: 4*size + 2*7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{
+				"a.txt":     "head-middle1-tail",
+				"b.txt":     "head-middle1-tail",
+				"c.txt":     "head-middle2-tail", // The same head and tail, only the full hash can find the difference
+				"d.txt":     "HEAD-middle1-tail", // The partial hash is enough to find the difference
+				"tiny1.txt": "1234567",           // Smaller than two blocks, hashed completely right away
+				"tiny2.txt": "1234567",
+			})
+			fc, err := NewFileChecker(Options{PartialBlockSize: tt.blockSize})
+			require.NoError(t, err)
+
+			// Act
+			filestest.CheckFiles(t, fc, paths)
+
+			// Assert
+			groups := fc.GetDuplicatedFileGroups()
+			require.Len(t, groups, 2)
+
+			var groupFiles [][]string
+			for _, group := range groups {
+				groupFiles = append(groupFiles, group.Files())
+			}
+			assert.ElementsMatch(
+				t,
+				[][]string{{paths["a.txt"], paths["b.txt"]}, {paths["tiny1.txt"], paths["tiny2.txt"]}},
+				groupFiles,
+			)
+
+			stats := fc.Stats()
+			assert.Equal(t, tt.partialHashedFiles, stats.PartialHashedFiles)
+			assert.Equal(t, tt.partialHashedBytes, stats.PartialHashedBytes)
+			assert.Equal(t, tt.hashedFiles, stats.HashedFiles)
+			assert.Equal(t, tt.hashedBytes, stats.HashedBytes)
+			assert.Equal(t, tt.savedBytes, stats.SavedBytes)
+		})
+	}
+}
+
+func TestCalculatePartialHash(t *testing.T) {
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{
+		"a.txt": "0123456789",
+		"b.txt": "01xxxxxx89",
+		"c.txt": "x123456789",
+	})
+
+	hashA, readBytes, err := calculatePartialHash(paths["a.txt"], 10, 2, HashSHA256)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), readBytes)
+
+	hashB, _, err := calculatePartialHash(paths["b.txt"], 10, 2, HashSHA256)
+	require.NoError(t, err)
+	assert.Equal(t, hashA, hashB, "The middle part should not affect the partial hash")
+
+	hashC, _, err := calculatePartialHash(paths["c.txt"], 10, 2, HashSHA256)
+	require.NoError(t, err)
+	assert.NotEqual(t, hashA, hashC, "The head should affect the partial hash")
+
+	_, _, err = calculatePartialHash(paths["a.txt"], 20, 2, HashSHA256)
+	assert.Error(t, err, "Truncated file should be reported")
+}
+
+func TestFileChecker_OnGroup(t *testing.T) {
+	// Arrange
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{
+		"a.txt": "abc",
+		"b.txt": "abc",
+		"c.txt": "abcde",
+		"d.txt": "abcde",
+		"e.txt": "vwxyz",
+	})
+	var notified []*FilesCheckGroup
+	fc, err := NewFileChecker(Options{OnGroup: func(group *FilesCheckGroup) {
+		notified = append(notified, group)
+	}})
+	require.NoError(t, err)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NoError(t, fc.Add(path, info.Size()))
+	}
+
+	// Act & Assert
+	_, err = fc.Check(paths["a.txt"])
+	require.NoError(t, err)
+	assert.Empty(t, notified, "The group is not complete before all files of its size are checked")
+
+	_, err = fc.Check(paths["b.txt"])
+	require.NoError(t, err)
+	require.Len(t, notified, 1, "The group should be reported before the files of other sizes are checked")
+	assert.Equal(t, []string{paths["a.txt"], paths["b.txt"]}, notified[0].Files())
+
+	_, err = fc.Check(paths["c.txt"])
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(paths["e.txt"]))
+	_, err = fc.Check(paths["e.txt"])
+	require.Error(t, err)
+	assert.Len(t, notified, 1)
+
+	_, err = fc.Check(paths["d.txt"])
+	require.NoError(t, err)
+	require.Len(t, notified, 2, "The failed file should not block the group")
+	assert.Equal(t, []string{paths["c.txt"], paths["d.txt"]}, notified[1].Files())
+}
+
+func TestFileChecker_OnGroupWithPartialHash(t *testing.T) {
+	// Arrange
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{
+		"a.txt":     "head-middle1-tail",
+		"b.txt":     "head-middle1-tail",
+		"c.txt":     "head-middle2-tail",
+		"d.txt":     "HEAD-middle1-tail",
+		"e.txt":     "HEAD-middle1-tail",
+		"tiny1.txt": "1234567",
+		"tiny2.txt": "1234567",
+	})
+	var mu sync.Mutex
+	var notified []*FilesCheckGroup
+	fc, err := NewFileChecker(Options{PartialBlockSize: 4, OnGroup: func(group *FilesCheckGroup) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, group)
+	}})
+	require.NoError(t, err)
+
+	// Act
+	filestest.CheckFiles(t, fc, paths)
+
+	// Assert
+	assert.ElementsMatch(t, fc.GetDuplicatedFileGroups(), notified, "Every group should be reported once")
+}
@@ -0,0 +1,60 @@
+package checkers
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// calculateHash returns the hash of the whole file content and the number of read bytes
+func calculateHash(path string, algorithm HashAlgorithm) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hasher, err := algorithm.New()
+	if err != nil {
+		return "", 0, err
+	}
+
+	readBytes, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to calculate hash: %w", err)
+	}
+
+	return fmt.Sprintf("%x", hasher.Sum(nil)), readBytes, nil
+}
+
+// calculatePartialHash returns the hash of the first and the last blockSize bytes of the file
+// and the number of read bytes. The file should be larger than two blocks.
+func calculatePartialHash(path string, size int64, blockSize int64, algorithm HashAlgorithm) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hasher, err := algorithm.New()
+	if err != nil {
+		return "", 0, err
+	}
+
+	headBytes, err := io.Copy(hasher, io.NewSectionReader(file, 0, blockSize))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to calculate partial hash of the head: %w", err)
+	}
+
+	tailBytes, err := io.Copy(hasher, io.NewSectionReader(file, size-blockSize, blockSize))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to calculate partial hash of the tail: %w", err)
+	}
+
+	// The file was changed after its size was taken
+	if headBytes+tailBytes != 2*blockSize {
+		return "", 0, fmt.Errorf("failed to calculate partial hash: file is shorter than expected %d bytes", size)
+	}
+
+	return fmt.Sprintf("%x", hasher.Sum(nil)), headBytes + tailBytes, nil
+}
@@ -0,0 +1,50 @@
+package checkers
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// CrossRootGroups returns the groups with files from at least two different roots.
+// A file belongs to the longest root containing it, so nested roots are handled as separate ones.
+func CrossRootGroups(groups []*FilesCheckGroup, roots []string) ([]*FilesCheckGroup, error) {
+	absRoots := make([]string, 0, len(roots))
+	for _, root := range roots {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path: %w", err)
+		}
+
+		absRoots = append(absRoots, absRoot)
+	}
+
+	var result []*FilesCheckGroup
+	for _, group := range groups {
+		foundRoots := make(map[string]bool)
+		for _, file := range group.Files() {
+			if root := fileRoot(file, absRoots); root != "" {
+				foundRoots[root] = true
+			}
+		}
+
+		if len(foundRoots) > 1 {
+			result = append(result, group)
+		}
+	}
+
+	return result, nil
+}
+
+// fileRoot returns the longest root containing the file
+func fileRoot(file string, roots []string) string {
+	result := ""
+	for _, root := range roots {
+		prefix := strings.TrimSuffix(root, string(filepath.Separator)) + string(filepath.Separator)
+		if strings.HasPrefix(file, prefix) && len(root) > len(result) {
+			result = root
+		}
+	}
+
+	return result
+}
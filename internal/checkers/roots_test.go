@@ -0,0 +1,44 @@
+package checkers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil/filestest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossRootGroups(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	paths := filestest.WriteFiles(t, dir, map[string]string{
+		"a/same-root-1.txt": "same root",
+		"a/same-root-2.txt": "same root",
+		"a/cross-root.txt":  "cross root",
+		"b/cross-root.txt":  "cross root",
+		"a/b/nested.txt":    "nested root",
+		"a/nested.txt":      "nested root",
+	})
+
+	fc, err := NewFileChecker(Options{})
+	require.NoError(t, err)
+	filestest.CheckFiles(t, fc, paths)
+	groups := fc.GetDuplicatedFileGroups()
+	require.Len(t, groups, 3)
+
+	// Act
+	result, err := CrossRootGroups(groups, []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "a/b")})
+
+	// Assert
+	require.NoError(t, err)
+	var resultFiles [][]string
+	for _, group := range result {
+		resultFiles = append(resultFiles, group.Files())
+	}
+
+	assert.ElementsMatch(t, [][]string{
+		{paths["a/cross-root.txt"], paths["b/cross-root.txt"]},
+		{paths["a/b/nested.txt"], paths["a/nested.txt"]},
+	}, resultFiles)
+}
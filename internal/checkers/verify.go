@@ -0,0 +1,180 @@
+package checkers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const verifyBufferSize = 64 * 1024
+
+// ErrContentMismatch means that the file has the same hash as the group, but different content.
+// It is either a hash collision or a file modified after it was hashed.
+var ErrContentMismatch = errors.New("content differs from the group")
+
+// VerificationIssue is a file which does not belong to its duplicated files group after the byte-by-byte comparison
+type VerificationIssue struct {
+	Hash      string // Hash of the group
+	Path      string // The file which cannot be verified
+	Reference string // The group member the file was compared with
+	Err       error
+}
+
+func (vi VerificationIssue) Error() string {
+	return fmt.Sprintf("%s (group %s): %v", vi.Path, vi.Hash, vi.Err)
+}
+
+func (vi VerificationIssue) Unwrap() error {
+	return vi.Err
+}
+
+// VerifyGroups compares the content of every group member with the first member byte-by-byte.
+// Groups with mismatched files are split by the actual content, unreadable files are removed.
+// The groups are verified in parallel, the result order is the same as the input one.
+func VerifyGroups(groups []*FilesCheckGroup, workers int) ([]*FilesCheckGroup, []VerificationIssue) {
+	type verifyResult struct {
+		groups []*FilesCheckGroup
+		issues []VerificationIssue
+	}
+
+	results := make([]verifyResult, len(groups))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				results[idx].groups, results[idx].issues = verifyGroup(groups[idx])
+			}
+		}()
+	}
+
+	for idx := range groups {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+
+	var verified []*FilesCheckGroup
+	var issues []VerificationIssue
+	for _, res := range results {
+		verified = append(verified, res.groups...)
+		issues = append(issues, res.issues...)
+	}
+
+	return verified, issues
+}
+
+// verifyGroup splits the group into subgroups of files with equal content.
+// Subgroups with a single file are not duplicates and are not returned.
+func verifyGroup(group *FilesCheckGroup) ([]*FilesCheckGroup, []VerificationIssue) {
+	var subgroups []*FilesCheckGroup
+	var issues []VerificationIssue
+
+	files := group.Files()
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			issues = append(issues, VerificationIssue{Hash: group.Hash(), Path: file, Err: err})
+			continue
+		}
+
+		// Looking for the subgroup with the same content, the first subgroup contains the first group member
+		matchedIdx := -1
+		var compareErr error
+		for idx, subgroup := range subgroups {
+			equal, err := compareFiles(subgroup.Files()[0], file)
+			if err != nil {
+				compareErr = err
+				break
+			}
+
+			if equal {
+				matchedIdx = idx
+				break
+			}
+		}
+
+		if compareErr != nil {
+			issues = append(issues, VerificationIssue{Hash: group.Hash(), Path: file, Err: compareErr})
+			continue // The file cannot be placed into any subgroup
+		}
+
+		if matchedIdx != 0 && len(subgroups) > 0 {
+			issues = append(issues, VerificationIssue{
+				Hash:      group.Hash(),
+				Path:      file,
+				Reference: subgroups[0].Files()[0],
+				Err:       ErrContentMismatch,
+			})
+		}
+
+		if matchedIdx >= 0 {
+			subgroups[matchedIdx].addFile(file)
+		} else {
+			subgroups = append(subgroups, newFilesCheckGroup(group.Algorithm(), group.Hash(), group.Size(), file))
+		}
+	}
+
+	var result []*FilesCheckGroup
+	for _, subgroup := range subgroups {
+		if subgroup.HasMultipleFiles() {
+			result = append(result, subgroup)
+		}
+	}
+
+	return result, issues
+}
+
+// compareFiles checks if the files have the same content
+func compareFiles(pathA, pathB string) (bool, error) {
+	fileA, err := os.Open(pathA)
+	if err != nil {
+		return false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer fileA.Close()
+
+	fileB, err := os.Open(pathB)
+	if err != nil {
+		return false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer fileB.Close()
+
+	bufA := make([]byte, verifyBufferSize)
+	bufB := make([]byte, verifyBufferSize)
+	for {
+		readA, errA := io.ReadFull(fileA, bufA)
+		readB, errB := io.ReadFull(fileB, bufB)
+
+		if !bytes.Equal(bufA[:readA], bufB[:readB]) {
+			return false, nil
+		}
+
+		endA, errA := isEndOfFile(errA)
+		if errA != nil {
+			return false, fmt.Errorf("failed to read file %s: %w", pathA, errA)
+		}
+
+		endB, errB := isEndOfFile(errB)
+		if errB != nil {
+			return false, fmt.Errorf("failed to read file %s: %w", pathB, errB)
+		}
+
+		if endA || endB {
+			return endA == endB, nil
+		}
+	}
+}
+
+// isEndOfFile converts io.ReadFull errors into the end of file flag and real read errors
+func isEndOfFile(err error) (bool, error) {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true, nil
+	}
+
+	return false, err
+}
@@ -0,0 +1,109 @@
+package checkers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/testutil/filestest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGroup creates a group from the given files as if they have the same hash
+func newTestGroup(hash string, files ...string) *FilesCheckGroup {
+	group := newFilesCheckGroup(HashSHA256, hash, 0, files[0])
+	for _, file := range files[1:] {
+		group.addFile(file)
+	}
+
+	return group
+}
+
+func TestVerifyGroups_EqualFiles(t *testing.T) {
+	// Arrange
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{"a.txt": "content", "b.txt": "content", "c.txt": "content"})
+	group := newTestGroup("hash", paths["a.txt"], paths["b.txt"], paths["c.txt"])
+
+	// Act
+	verified, issues := VerifyGroups([]*FilesCheckGroup{group}, 2)
+
+	// Assert
+	assert.Empty(t, issues)
+	require.Len(t, verified, 1)
+	assert.Equal(t, group.Files(), verified[0].Files())
+	assert.Equal(t, "hash", verified[0].Hash())
+}
+
+func TestVerifyGroups_SplitsMismatchedGroup(t *testing.T) {
+	// Arrange
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{
+		"a.txt": "content-1",
+		"b.txt": "content-2",
+		"c.txt": "content-1",
+		"d.txt": "content-2",
+		"e.txt": "content-3",
+	})
+	group := newTestGroup("hash", paths["a.txt"], paths["b.txt"], paths["c.txt"], paths["d.txt"], paths["e.txt"])
+
+	// Act
+	verified, issues := VerifyGroups([]*FilesCheckGroup{group}, 1)
+
+	// Assert
+	require.Len(t, verified, 2, "e.txt has no duplicates and should not be returned")
+	assert.Equal(t, []string{paths["a.txt"], paths["c.txt"]}, verified[0].Files())
+	assert.Equal(t, []string{paths["b.txt"], paths["d.txt"]}, verified[1].Files())
+
+	require.Len(t, issues, 3, "All files different from the first one should be reported")
+	for i, path := range []string{paths["b.txt"], paths["d.txt"], paths["e.txt"]} {
+		assert.Equal(t, path, issues[i].Path)
+		assert.Equal(t, paths["a.txt"], issues[i].Reference)
+		assert.ErrorIs(t, issues[i], ErrContentMismatch)
+	}
+}
+
+func TestVerifyGroups_MissingFile(t *testing.T) {
+	// Arrange
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{"a.txt": "content", "b.txt": "content", "c.txt": "content"})
+	group := newTestGroup("hash", paths["a.txt"], paths["b.txt"], paths["c.txt"])
+	require.NoError(t, os.Remove(paths["b.txt"]))
+
+	// Act
+	verified, issues := VerifyGroups([]*FilesCheckGroup{group}, 1)
+
+	// Assert
+	require.Len(t, verified, 1)
+	assert.Equal(t, []string{paths["a.txt"], paths["c.txt"]}, verified[0].Files())
+
+	require.Len(t, issues, 1)
+	assert.Equal(t, paths["b.txt"], issues[0].Path)
+	assert.ErrorIs(t, issues[0], os.ErrNotExist)
+}
+
+func TestCompareFiles(t *testing.T) {
+	paths := filestest.WriteFiles(t, t.TempDir(), map[string]string{
+		"a.txt":      "content",
+		"b.txt":      "content",
+		"c.txt":      "Content",
+		"longer.txt": "content and more",
+	})
+
+	tests := []struct {
+		name     string
+		pathA    string
+		pathB    string
+		expected bool
+	}{
+		{name: "equal files", pathA: paths["a.txt"], pathB: paths["b.txt"], expected: true},
+		{name: "different content", pathA: paths["a.txt"], pathB: paths["c.txt"], expected: false},
+		{name: "file is a prefix of another one", pathA: paths["a.txt"], pathB: paths["longer.txt"], expected: false},
+		{name: "longer file first", pathA: paths["longer.txt"], pathB: paths["a.txt"], expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, err := compareFiles(tt.pathA, tt.pathB)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, equal)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package glob matches slash-separated relative paths against glob patterns.
+// Besides the path.Match syntax, the "**" segment matches any number of path segments.
+package glob
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Pattern is a compiled glob pattern.
+// A pattern without slashes matches the name at any depth, like "**/name" does.
+// A pattern with slashes is anchored to the root, a leading slash is optional.
+type Pattern struct {
+	source   string
+	segments []string
+}
+
+func Compile(pattern string) (*Pattern, error) {
+	trimmed := strings.TrimSuffix(pattern, "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty glob pattern: %q", pattern)
+	}
+
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	var segments []string
+	if !anchored {
+		segments = append(segments, "**")
+	}
+
+	for _, segment := range strings.Split(trimmed, "/") {
+		if segment == "**" && len(segments) > 0 && segments[len(segments)-1] == "**" {
+			continue // Repeated "**" segments match the same paths as a single one
+		}
+
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return &Pattern{source: pattern, segments: segments}, nil
+}
+
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Match checks if the relative path matches the pattern
+func (p *Pattern) Match(name string) bool {
+	return matchSegments(p.segments, splitPath(name))
+}
+
+// MatchPrefix checks if a path inside the directory can match the pattern
+func (p *Pattern) MatchPrefix(dir string) bool {
+	pattern := p.segments
+	for _, segment := range splitPath(dir) {
+		if len(pattern) == 0 {
+			return false
+		}
+
+		if pattern[0] == "**" {
+			return true
+		}
+
+		if !matchSegment(pattern[0], segment) {
+			return false
+		}
+
+		pattern = pattern[1:]
+	}
+
+	return len(pattern) > 0
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			for skipped := 0; skipped <= len(name); skipped++ {
+				if matchSegments(pattern, name[skipped:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 || !matchSegment(pattern[0], name[0]) {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
+
+func matchSegment(pattern string, name string) bool {
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+func splitPath(name string) []string {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return nil
+	}
+
+	return strings.Split(name, "/")
+}
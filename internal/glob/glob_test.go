@@ -0,0 +1,75 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "node_modules", name: "node_modules", expected: true},
+		{pattern: "node_modules", name: "web/app/node_modules", expected: true},
+		{pattern: "node_modules", name: "web/node_modules_backup", expected: false},
+		{pattern: "*.jpg", name: "photos/2020/a.jpg", expected: true},
+		{pattern: "*.jpg", name: "photos/2020/a.jpeg", expected: false},
+		{pattern: "photos/*.jpg", name: "photos/a.jpg", expected: true},
+		{pattern: "photos/*.jpg", name: "photos/2020/a.jpg", expected: false},
+		{pattern: "photos/*.jpg", name: "backup/photos/a.jpg", expected: false},
+		{pattern: "/photos/*.jpg", name: "photos/a.jpg", expected: true},
+		{pattern: "photos/**/*.jpg", name: "photos/a.jpg", expected: true},
+		{pattern: "photos/**/*.jpg", name: "photos/2020/01/a.jpg", expected: true},
+		{pattern: "photos/**", name: "photos/2020/01/a.jpg", expected: true},
+		{pattern: "**/cache/**", name: "a/cache/b/c.txt", expected: true},
+		{pattern: "**/cache/**", name: "a/caches/b/c.txt", expected: false},
+		{pattern: "a/**/**/b", name: "a/x/b", expected: true},
+		{pattern: "file-?.[ct]xt", name: "file-1.txt", expected: true},
+		{pattern: "file-?.[ct]xt", name: "file-10.txt", expected: false},
+		{pattern: "build/", name: "src/build", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			pattern, err := Compile(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pattern.Match(tt.name))
+		})
+	}
+}
+
+func TestPattern_MatchPrefix(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		dir      string
+		expected bool
+	}{
+		{pattern: "*.jpg", dir: "any/dir", expected: true},
+		{pattern: "photos/*.jpg", dir: "photos", expected: true},
+		{pattern: "photos/*.jpg", dir: "music", expected: false},
+		{pattern: "photos/*.jpg", dir: "photos/2020", expected: false},
+		{pattern: "photos/**/*.jpg", dir: "photos/2020/01", expected: true},
+		{pattern: "*/raw/*", dir: "camera", expected: true},
+		{pattern: "*/raw/*", dir: "camera/raw", expected: true},
+		{pattern: "*/raw/*", dir: "camera/jpeg", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.dir, func(t *testing.T) {
+			pattern, err := Compile(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pattern.MatchPrefix(tt.dir))
+		})
+	}
+}
+
+func TestCompile_InvalidPattern(t *testing.T) {
+	for _, pattern := range []string{"", "/", "photos/[a-"} {
+		_, err := Compile(pattern)
+		assert.Error(t, err, "pattern %q", pattern)
+	}
+}
@@ -0,0 +1,44 @@
+package parameters
+
+import "fmt"
+
+// Command is a name of the subcommand
+type Command string
+
+const (
+	CommandScan    Command = "scan"    // Scan the paths and show the overview of the duplicates
+	CommandReport  Command = "report"  // Scan the paths and write the duplicated groups report
+	CommandDedupe  Command = "dedupe"  // Scan the paths and process the duplicates
+	CommandVerify  Command = "verify"  // Scan the paths and compare the duplicates byte-by-byte
+	CommandCompare Command = "compare" // Scan the paths and report only the duplicates found in different paths
+	CommandServe   Command = "serve"   // Scan the paths and serve the report over HTTP
+	CommandUndo    Command = "undo"    // Undo the changes recorded in the dedupe journal
+)
+
+var commands = []Command{CommandScan, CommandReport, CommandDedupe, CommandVerify, CommandCompare, CommandServe, CommandUndo}
+
+// commandUsage describes the positional arguments and the purpose of the command
+type commandUsage struct {
+	arguments   string
+	description string
+}
+
+var commandUsages = map[Command]commandUsage{
+	CommandScan:    {arguments: "[PATH...]", description: "Scan the paths and show the overview of the duplicates"},
+	CommandReport:  {arguments: "[PATH...]", description: "Scan the paths and write the report of the duplicated files"},
+	CommandDedupe:  {arguments: "[PATH...]", description: "Scan the paths, keep one file of every duplicated group and process the others"},
+	CommandVerify:  {arguments: "[PATH...]", description: "Scan the paths and compare the duplicated files byte-by-byte"},
+	CommandCompare: {arguments: "PATH PATH...", description: "Scan the paths and report only the duplicates found in different paths"},
+	CommandServe:   {arguments: "[PATH...]", description: "Scan the paths and serve the report over HTTP"},
+	CommandUndo:    {arguments: "JOURNAL", description: "Undo the changes recorded in the dedupe journal"},
+}
+
+func ParseCommand(name string) (Command, error) {
+	for _, command := range commands {
+		if string(command) == name {
+			return command, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown command: %q", name)
+}
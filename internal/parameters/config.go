@@ -0,0 +1,134 @@
+package parameters
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The config key with the named sets of options
+const profilesKey = "profiles"
+
+// loadConfig reads the YAML or JSON configuration file. The keys of the file are the flag names,
+// the "profiles" key contains named sets of options overriding the top level ones.
+// The result contains the options of the chosen profile merged with the top level options.
+func loadConfig(path string, profile string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var config map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber() // Large integers should not be converted to the exponent notation
+		err = decoder.Decode(&config)
+	} else {
+		err = yaml.Unmarshal(content, &config)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	profiles, ok := config[profilesKey].(map[string]any)
+	if _, found := config[profilesKey]; found && !ok {
+		return nil, fmt.Errorf("config profiles should be a mapping of profile names to options")
+	}
+	delete(config, profilesKey)
+
+	if profile == "" {
+		return config, nil
+	}
+
+	profileOptions, ok := profiles[profile].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config profile %q is not found", profile)
+	}
+
+	maps.Copy(config, profileOptions)
+	return config, nil
+}
+
+// applyConfig sets the flags which were not passed explicitly to the config values.
+// The options of the flags unknown to the command are ignored, if they are known to other commands.
+func (p *runParametersParser) applyConfig(flagSet *flag.FlagSet, config map[string]any, skipPaths bool) error {
+	explicit := make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	for _, name := range slices.Sorted(maps.Keys(config)) {
+		if explicit[name] || (name == "path" && skipPaths) {
+			continue
+		}
+
+		if flagSet.Lookup(name) == nil {
+			if !p.isKnownFlag(name) || name == "config" || name == "profile" {
+				return fmt.Errorf("unknown config option: %q", name)
+			}
+
+			continue
+		}
+
+		values, err := configValues(config[name])
+		if err != nil {
+			return fmt.Errorf("invalid config option %q: %w", name, err)
+		}
+
+		for _, value := range values {
+			if err := flagSet.Set(name, value); err != nil {
+				return fmt.Errorf("invalid config option %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isKnownFlag checks if the flag is accepted by any of the commands
+func (p *runParametersParser) isKnownFlag(name string) bool {
+	for _, command := range append([]Command{""}, commands...) {
+		flagSet, _ := p.initFlagSet(command)
+		if flagSet.Lookup(name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// configValues converts the config value to the flag values, a list sets the flag multiple times
+func configValues(value any) ([]string, error) {
+	switch typed := value.(type) {
+	case []any:
+		var result []string
+		for _, item := range typed {
+			itemValues, err := configValues(item)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, itemValues...)
+		}
+
+		return result, nil
+	case map[string]any:
+		return nil, fmt.Errorf("a scalar value or a list is expected")
+	case time.Time:
+		return []string{typed.Format(time.RFC3339)}, nil // YAML timestamps, e.g. the newer-than dates
+	case nil:
+		return nil, nil
+	}
+
+	return []string{fmt.Sprint(value)}, nil
+}
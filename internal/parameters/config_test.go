@@ -0,0 +1,144 @@
+package parameters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAMLConfig = `
+path: [/config/default]
+hash: xxh64
+workers: 3
+dedupe-mode: hardlink # Ignored by the commands without dedupe flags
+profiles:
+  photos:
+    path:
+      - /config/photos1
+      - /config/photos2
+    format: json
+    verify: true
+  archive:
+    newer-than: 2020-01-31
+    older-than: 365d
+    min-size: 4K
+  broken:
+    workers: many
+`
+
+const testJSONConfig = `{
+  "path": ["/config/default"],
+  "hash": "md5",
+  "partial-kb": 1048576,
+  "profiles": {"backups": {"path": ["/config/backups"], "skipempty": true}}
+}`
+
+func TestParseConfig(t *testing.T) {
+	dir := t.TempDir()
+	yamlConfig := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(yamlConfig, []byte(testYAMLConfig), 0644))
+	jsonConfig := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(jsonConfig, []byte(testJSONConfig), 0644))
+	unknownOptionConfig := filepath.Join(dir, "unknown.yaml")
+	require.NoError(t, os.WriteFile(unknownOptionConfig, []byte("paths: [/config/path]\n"), 0644))
+
+	tests := []struct {
+		name    string
+		args    []string
+		check   func(t *testing.T, params *RunParameters)
+		wantErr bool
+	}{
+		{
+			name: "top level options",
+			args: []string{"prog", "report", "-config", yamlConfig},
+			check: func(t *testing.T, params *RunParameters) {
+				assert.Equal(t, []string{"/config/default"}, params.Paths)
+				assert.Equal(t, checkers.HashXXH64, params.HashAlgorithm)
+				assert.Equal(t, 3, params.Workers)
+				assert.Equal(t, report.FormatText, params.Format)
+			},
+		},
+		{
+			name: "profile overrides top level options",
+			args: []string{"prog", "report", "-config", yamlConfig, "-profile", "photos"},
+			check: func(t *testing.T, params *RunParameters) {
+				assert.Equal(t, []string{"/config/photos1", "/config/photos2"}, params.Paths)
+				assert.Equal(t, checkers.HashXXH64, params.HashAlgorithm)
+				assert.Equal(t, report.FormatJSON, params.Format)
+				assert.True(t, params.Verify)
+			},
+		},
+		{
+			name: "filter options",
+			args: []string{"prog", "scan", "-config", yamlConfig, "-profile", "archive"},
+			check: func(t *testing.T, params *RunParameters) {
+				assert.True(t, time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC).Equal(params.NewerThan))
+				assert.WithinDuration(t, time.Now().AddDate(0, 0, -365), params.OlderThan, time.Minute)
+				assert.Equal(t, int64(4096), params.MinSize)
+			},
+		},
+		{
+			name: "flags override config options",
+			args: []string{"prog", "report", "-config", yamlConfig, "-profile", "photos", "-format", "csv", "-workers", "5", "/cli/path"},
+			check: func(t *testing.T, params *RunParameters) {
+				assert.Equal(t, []string{"/cli/path"}, params.Paths)
+				assert.Equal(t, report.FormatCSV, params.Format)
+				assert.Equal(t, 5, params.Workers)
+			},
+		},
+		{
+			name: "json config",
+			args: []string{"prog", "scan", "-config", jsonConfig, "-profile", "backups"},
+			check: func(t *testing.T, params *RunParameters) {
+				assert.Equal(t, []string{"/config/backups"}, params.Paths)
+				assert.Equal(t, checkers.HashMD5, params.HashAlgorithm)
+				assert.Equal(t, 1048576, params.PartialKB)
+				assert.True(t, params.SkipEmptyFiles)
+			},
+		},
+		{
+			name:    "unknown profile",
+			args:    []string{"prog", "report", "-config", yamlConfig, "-profile", "music"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid option value",
+			args:    []string{"prog", "report", "-config", yamlConfig, "-profile", "broken"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown option",
+			args:    []string{"prog", "report", "-config", unknownOptionConfig},
+			wantErr: true,
+		},
+		{
+			name:    "missing config",
+			args:    []string{"prog", "report", "-config", filepath.Join(dir, "missing.yaml")},
+			wantErr: true,
+		},
+		{
+			name:    "profile without config",
+			args:    []string{"prog", "report", "-profile", "photos", "/cli/path"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := NewRunParametersParser().Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err, "An error is expected")
+				return
+			}
+
+			require.NoError(t, err)
+			tt.check(t, params)
+		})
+	}
+}
@@ -3,41 +3,302 @@ package parameters
 import (
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/actions"
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/glob"
+	"github.com/pryazhnikov/gofileschecker/internal/report"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
+)
+
+// LogFormat is a format of the log lines
+type LogFormat string
+
+const (
+	LogFormatJSON    LogFormat = "json"
+	LogFormatConsole LogFormat = "console" // Human-readable colored lines
 )
 
 type RunParameters struct {
-	Paths          []string // Paths to directories for scanning
-	Debug          bool     // Enable debug logging
-	FullFilePath   bool     // Show full file paths in output
-	SkipEmptyFiles bool     // Do not process empty files
+	Command        Command               // The subcommand to run
+	Config         string                // Path to the YAML or JSON file with the default flag values
+	Profile        string                // Name of the config profile
+	Paths          []string              // Paths to directories for scanning
+	Debug          bool                  // Enable debug logging
+	FullFilePath   bool                  // Show full file paths in output
+	SkipEmptyFiles bool                  // Do not process empty files, the same as MinSize of 1 byte
+	MinSize        int64                 // Files smaller than the size in bytes are skipped
+	MaxSize        int64                 // Files larger than the size in bytes are skipped, no limit if 0
+	NewerThan      time.Time             // Files modified before the time are skipped, no limit if zero
+	OlderThan      time.Time             // Files modified after the time are skipped, no limit if zero
+	Symlinks       scanner.SymlinkPolicy // What to do with symbolic links
+	Workers        int                   // Number of files checked in parallel
+	PartialKB      int                   // Size in KB of the file head and tail hashed before the full hash
+	Verify         bool                  // Compare duplicated files byte-by-byte
+	Include        []string              // Glob patterns of the scanned files, all files are scanned if empty
+	Exclude        []string              // Glob patterns of the skipped files and directories
+	IgnoreFiles    bool                  // Skip the paths ignored by the .gitignore-style files
+
+	HashAlgorithm checkers.HashAlgorithm // Algorithm used to calculate file hashes
+	Format        report.Format          // Output format of the results
+
+	LogFile   string    // Path to the log file, logs are written to stderr if empty
+	LogFormat LogFormat // Format of the log lines
+	Quiet     bool      // Log errors only, the results are still written
+
+	Dedupe      bool               // Keep one file of every duplicated group and process the others
+	DedupeMode  actions.DedupeMode // What is done with the duplicates which are not kept
+	LinkStyle   actions.LinkStyle  // How symbolic links point to the kept file
+	Quarantine  string             // Directory for the quarantined duplicates
+	KeepPolicy  actions.KeepPolicy // Which file of a group is kept
+	KeepRegex   string             // Pattern of the kept file for the regex keep policy
+	DryRun      bool               // Only log the planned dedupe actions
+	Confirm     bool               // Explicit confirmation of the dedupe actions
+	Journal     string             // Path to the journal of the dedupe changes
+	Script      string             // Path to the shell script with the dedupe commands, the files are not changed
+	Interactive bool               // Ask the user which file of every group should be kept
+	Undo        string             // Path to the journal which changes should be reversed instead of scanning
+
+	ErrorPolicy scanner.ErrorPolicy // What to do when a file cannot be processed
+	MaxErrors   int                 // Errors limit for the abort-after error policy
+
+	Listen string // Address of the HTTP server for the serve command
 }
 
 type runParametersParser struct {
 	parsedParams *RunParameters
+	program      string  // Program name used in the usage text
+	command      Command // The command of the last Parse call, empty without a command
 }
 
 func NewRunParametersParser() *runParametersParser {
 	parser := &runParametersParser{
 		parsedParams: nil, // It should be initialized at Parse() method
+		program:      "gofilechecker",
 	}
 
 	return parser
 }
 
-func (p *runParametersParser) initFlagSet(name string) (*flag.FlagSet, *RunParameters) {
+// initFlagSet creates the flag set of the command. Without a command all flags are accepted
+// to keep the command lines written before the subcommands were introduced working.
+func (p *runParametersParser) initFlagSet(command Command) (*flag.FlagSet, *RunParameters) {
 	parsedParams := &RunParameters{
-		Paths: make([]string, 0),
+		Command:       command,
+		Paths:         make([]string, 0),
+		ErrorPolicy:   scanner.ErrorPolicyContinue,
+		HashAlgorithm: checkers.DefaultHashAlgorithm,
+		Format:        report.FormatText,
+		LogFormat:     LogFormatJSON,
+		KeepPolicy:    actions.KeepOldest,
+		DedupeMode:    actions.DedupeRemove,
+		LinkStyle:     actions.LinkRelative,
+		Workers:       runtime.NumCPU(),
+		PartialKB:     4,
+		MaxErrors:     10,
+		DryRun:        true,
 	}
+
+	name := p.program
+	if command != "" {
+		name += " " + string(command)
+	}
+
 	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.StringVar(&parsedParams.Config, "config", "", "YAML or JSON file with the flag values, the command line flags override them")
+	flagSet.StringVar(&parsedParams.Profile, "profile", "", "Name of the config profile overriding the top level config values")
+	addLogFlags(flagSet, parsedParams)
+	if command != CommandUndo {
+		addScanFlags(flagSet, parsedParams)
+	}
+
+	if command != CommandVerify && command != CommandUndo {
+		flagSet.BoolVar(&parsedParams.Verify, "verify", false, "Compare duplicated files byte-by-byte after hashing, always enabled when dedupe changes files")
+	}
+
+	switch command {
+	case "":
+		addReportFlags(flagSet, parsedParams)
+		addDedupeFlags(flagSet, parsedParams)
+		flagSet.BoolVar(&parsedParams.Dedupe, "dedupe", false, "Keep one file of every duplicated group and process the others")
+		flagSet.StringVar(&parsedParams.Undo, "undo", "", "Undo the changes recorded in the journal file instead of scanning")
+	case CommandReport, CommandVerify, CommandCompare:
+		addReportFlags(flagSet, parsedParams)
+	case CommandDedupe:
+		addReportFlags(flagSet, parsedParams)
+		addDedupeFlags(flagSet, parsedParams)
+	case CommandServe:
+		flagSet.StringVar(&parsedParams.Listen, "listen", "localhost:8080", "Address of the HTTP server")
+	}
+
+	return flagSet, parsedParams
+}
+
+func addLogFlags(flagSet *flag.FlagSet, parsedParams *RunParameters) {
 	flagSet.BoolVar(&parsedParams.Debug, "debug", false, "Enable debug logging")
-	flagSet.BoolVar(&parsedParams.FullFilePath, "fullpath", false, "Show full file paths in output")
-	flagSet.BoolVar(&parsedParams.SkipEmptyFiles, "skipempty", false, "Skip empty files during scanning")
-	flagSet.Func("path", "Path to directory for scanning (multiple usage is allowed)", func(flagValue string) error {
+	flagSet.StringVar(&parsedParams.LogFile, "logfile", "", "Write logs to the file instead of stderr")
+	flagSet.BoolVar(&parsedParams.Quiet, "quiet", false, "Log errors only, the results are still written")
+	flagSet.Func("log-format", "Log format: json, console (default json)", func(flagValue string) error {
+		switch format := LogFormat(flagValue); format {
+		case LogFormatJSON, LogFormatConsole:
+			parsedParams.LogFormat = format
+			return nil
+		}
+
+		return fmt.Errorf("unknown log format: %q", flagValue)
+	})
+}
+
+func addScanFlags(flagSet *flag.FlagSet, parsedParams *RunParameters) {
+	flagSet.BoolVar(&parsedParams.SkipEmptyFiles, "skipempty", false, "Skip empty files during scanning, the same as -min-size 1")
+	flagSet.Func("min-size", "Skip files smaller than the size, K, M, G and T units are allowed, e.g. 10K", func(flagValue string) error {
+		size, err := ParseSize(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.MinSize = size
+		return nil
+	})
+	flagSet.Func("max-size", "Skip files larger than the size, K, M, G and T units are allowed, e.g. 4G (default no limit)", func(flagValue string) error {
+		size, err := ParseSize(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.MaxSize = size
+		return nil
+	})
+	flagSet.IntVar(&parsedParams.Workers, "workers", parsedParams.Workers, "Number of files checked in parallel")
+	flagSet.IntVar(&parsedParams.PartialKB, "partial-kb", parsedParams.PartialKB, "Size in KB of the file head and tail hashed before the full hash (0 disables)")
+	flagSet.IntVar(&parsedParams.MaxErrors, "max-errors", parsedParams.MaxErrors, "Errors limit for the abort-after error policy")
+	hashUsage := fmt.Sprintf(
+		"Hash algorithm: %s (default %s)",
+		strings.Join(checkers.HashAlgorithmNames(), ", "),
+		checkers.DefaultHashAlgorithm,
+	)
+	flagSet.Func("hash", hashUsage, func(flagValue string) error {
+		algorithm, err := checkers.ParseHashAlgorithm(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.HashAlgorithm = algorithm
+		return nil
+	})
+	flagSet.Func("on-error", "Error policy: continue, abort, abort-after (default continue)", func(flagValue string) error {
+		policy, err := scanner.ParseErrorPolicy(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.ErrorPolicy = policy
+		return nil
+	})
+	flagSet.Func("include", "Glob pattern of the scanned files, others are skipped (multiple usage is allowed)", func(flagValue string) error {
+		if _, err := glob.Compile(flagValue); err != nil {
+			return err
+		}
+
+		parsedParams.Include = append(parsedParams.Include, flagValue)
+		return nil
+	})
+	flagSet.Func("exclude", "Glob pattern of the skipped files and directories, e.g. node_modules or **/*.tmp (multiple usage is allowed)", func(flagValue string) error {
+		if _, err := glob.Compile(flagValue); err != nil {
+			return err
+		}
+
+		parsedParams.Exclude = append(parsedParams.Exclude, flagValue)
+		return nil
+	})
+	flagSet.BoolVar(&parsedParams.IgnoreFiles, "ignore-files", false, "Skip the paths ignored by .gitignore and .gofilecheckerignore files found in the scanned directories")
+	flagSet.Func("newer-than", "Skip files modified before the date (e.g. 2024-01-31) or the duration ago (e.g. 30d, 2w, 12h)", func(flagValue string) error {
+		limit, err := ParseTimeLimit(flagValue, time.Now())
+		if err != nil {
+			return err
+		}
+
+		parsedParams.NewerThan = limit
+		return nil
+	})
+	flagSet.Func("older-than", "Skip files modified after the date (e.g. 2024-01-31) or the duration ago (e.g. 30d, 2w, 12h)", func(flagValue string) error {
+		limit, err := ParseTimeLimit(flagValue, time.Now())
+		if err != nil {
+			return err
+		}
+
+		parsedParams.OlderThan = limit
+		return nil
+	})
+	flagSet.Func("symlinks", "Symbolic links policy: skip, follow, report (default skip)", func(flagValue string) error {
+		policy, err := scanner.ParseSymlinkPolicy(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.Symlinks = policy
+		return nil
+	})
+	flagSet.Func("path", "Path to directory for scanning, an alternative to the positional arguments (multiple usage is allowed)", func(flagValue string) error {
 		parsedParams.Paths = append(parsedParams.Paths, flagValue)
 		return nil
 	})
+}
 
-	return flagSet, parsedParams
+func addReportFlags(flagSet *flag.FlagSet, parsedParams *RunParameters) {
+	flagSet.BoolVar(&parsedParams.FullFilePath, "fullpath", false, "Show full file paths in output")
+	flagSet.Func("format", "Output format: text, json, ndjson, csv (default text)", func(flagValue string) error {
+		format, err := report.ParseFormat(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.Format = format
+		return nil
+	})
+}
+
+func addDedupeFlags(flagSet *flag.FlagSet, parsedParams *RunParameters) {
+	flagSet.StringVar(&parsedParams.Quarantine, "quarantine", "", "Move the duplicates into the directory mirroring their paths (implies -dedupe -dedupe-mode quarantine)")
+	flagSet.StringVar(&parsedParams.Script, "script", "", "Write the dedupe commands to the shell script instead of running them (implies -dedupe)")
+	flagSet.BoolVar(&parsedParams.Interactive, "interactive", false, "Choose the kept file of every group in the terminal, the changes are applied after the confirmation (implies -dedupe)")
+	flagSet.StringVar(&parsedParams.Journal, "journal", "", "Append the dedupe changes to the journal file, it allows to undo them (default gofileschecker-journal-<time>.ndjson unless dry run)")
+	flagSet.StringVar(&parsedParams.KeepRegex, "keep-regex", "", "Pattern of the kept file for the regex keep policy")
+	flagSet.BoolVar(&parsedParams.DryRun, "dry-run", parsedParams.DryRun, "Only log the planned dedupe actions")
+	flagSet.BoolVar(&parsedParams.Confirm, "confirm", false, "Confirm the dedupe actions, required with -dry-run=false")
+	flagSet.Func("keep", "Keep policy: oldest, newest, shortest-path, longest-path, first-root, regex (default oldest)", func(flagValue string) error {
+		policy, err := actions.ParseKeepPolicy(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.KeepPolicy = policy
+		return nil
+	})
+	flagSet.Func("dedupe-mode", "Dedupe mode: remove, hardlink, symlink, reflink, quarantine (default remove)", func(flagValue string) error {
+		mode, err := actions.ParseDedupeMode(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.DedupeMode = mode
+		return nil
+	})
+	flagSet.Func("link-style", "Symbolic link style: relative, absolute (default relative)", func(flagValue string) error {
+		style, err := actions.ParseLinkStyle(flagValue)
+		if err != nil {
+			return err
+		}
+
+		parsedParams.LinkStyle = style
+		return nil
+	})
 }
 
 func (p *runParametersParser) Parse(args []string) (*RunParameters, error) {
@@ -45,25 +306,217 @@ func (p *runParametersParser) Parse(args []string) (*RunParameters, error) {
 		return nil, fmt.Errorf("no arguments provided")
 	}
 
-	flagSet, parsedParams := p.initFlagSet(args[0])
-	if err := flagSet.Parse(args[1:]); err != nil {
+	// The command is optional, the flags of all commands are accepted without it
+	p.program = filepath.Base(args[0])
+	p.command = ""
+	args = args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, err := ParseCommand(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		p.command = command
+		args = args[1:]
+	}
+
+	flagSet, parsedParams := p.initFlagSet(p.command)
+	flagSet.SetOutput(io.Discard) // The errors are returned, the usage is shown by the caller
+	positional, err := parseInterleaved(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsedParams.Config != "" {
+		config, err := loadConfig(parsedParams.Config, parsedParams.Profile)
+		if err != nil {
+			return nil, err
+		}
+
+		// The paths from the command line replace the configured ones instead of adding to them
+		skipPaths := len(positional) > 0 || isFlagSet(flagSet, "path")
+		if err := p.applyConfig(flagSet, config, skipPaths); err != nil {
+			return nil, err
+		}
+	} else if parsedParams.Profile != "" {
+		return nil, fmt.Errorf("config profile requires -config parameter")
+	}
+
+	if parsedParams.Quarantine != "" {
+		if isFlagSet(flagSet, "dedupe-mode") && parsedParams.DedupeMode != actions.DedupeQuarantine {
+			return nil, fmt.Errorf("quarantine directory cannot be used with %s dedupe mode", parsedParams.DedupeMode)
+		}
+
+		parsedParams.Dedupe = true
+		parsedParams.DedupeMode = actions.DedupeQuarantine
+	}
+
+	if parsedParams.Script != "" {
+		parsedParams.Dedupe = true
+	}
+
+	if parsedParams.Interactive {
+		if parsedParams.Script != "" {
+			return nil, fmt.Errorf("interactive review cannot be used with the script output")
+		}
+
+		// The review ends with the explicit confirmation, so the changes are applied unless dry run is requested
+		parsedParams.Dedupe = true
+		if !isFlagSet(flagSet, "dry-run") {
+			parsedParams.DryRun = false
+		}
+	}
+
+	if err := applyCommand(parsedParams, positional); err != nil {
 		return nil, err
 	}
 
 	// Validate required parameters
 	p.parsedParams = parsedParams
-	if len(parsedParams.Paths) == 0 {
+	if len(parsedParams.Paths) == 0 && parsedParams.Command != CommandUndo {
 		return nil, fmt.Errorf("at least one path parameter is required")
 	}
 
+	if len(parsedParams.Paths) < 2 && parsedParams.Command == CommandCompare {
+		return nil, fmt.Errorf("at least two paths are required to compare them")
+	}
+
+	if parsedParams.Workers < 1 {
+		return nil, fmt.Errorf("workers count should be positive, got %d", parsedParams.Workers)
+	}
+
+	if parsedParams.PartialKB < 0 {
+		return nil, fmt.Errorf("partial hash size should not be negative, got %d", parsedParams.PartialKB)
+	}
+
+	if parsedParams.SkipEmptyFiles {
+		parsedParams.MinSize = max(parsedParams.MinSize, 1)
+	}
+
+	if parsedParams.MaxSize > 0 && parsedParams.MinSize > parsedParams.MaxSize {
+		return nil, fmt.Errorf("min size %d should not exceed max size %d", parsedParams.MinSize, parsedParams.MaxSize)
+	}
+
+	if !parsedParams.NewerThan.IsZero() && !parsedParams.OlderThan.IsZero() && !parsedParams.NewerThan.Before(parsedParams.OlderThan) {
+		return nil, fmt.Errorf("newer-than time should be before older-than time")
+	}
+
+	if parsedParams.Dedupe && !parsedParams.DryRun && !parsedParams.Confirm && parsedParams.Script == "" && !parsedParams.Interactive {
+		return nil, fmt.Errorf("dedupe without dry run changes files, it requires -confirm flag")
+	}
+
+	// The files are changed only after the byte-by-byte comparison, the hashes may collide
+	if parsedParams.Dedupe && !parsedParams.DryRun && parsedParams.Script == "" {
+		if isFlagSet(flagSet, "verify") && !parsedParams.Verify {
+			return nil, fmt.Errorf("dedupe without dry run changes files, it cannot be used with -verify=false")
+		}
+
+		parsedParams.Verify = true
+	}
+
+	if parsedParams.DedupeMode == actions.DedupeQuarantine && parsedParams.Quarantine == "" {
+		return nil, fmt.Errorf("quarantine dedupe mode requires -quarantine parameter")
+	}
+
+	if parsedParams.KeepPolicy == actions.KeepRegex && parsedParams.KeepRegex == "" {
+		return nil, fmt.Errorf("regex keep policy requires -keep-regex parameter")
+	}
+
+	if parsedParams.ErrorPolicy == scanner.ErrorPolicyAbortAfterN && parsedParams.MaxErrors < 1 {
+		return nil, fmt.Errorf("max errors should be positive, got %d", parsedParams.MaxErrors)
+	}
+
 	return parsedParams, nil
 }
 
+// parseInterleaved parses the flags placed both before and after the positional arguments
+func parseInterleaved(flagSet *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+
+		// All arguments after the "--" terminator are positional ones
+		parsed := len(args) - flagSet.NArg()
+		if parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, flagSet.Args()...), nil
+		}
+
+		args = flagSet.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// applyCommand fills the parameters implied by the command and its positional arguments
+func applyCommand(parsedParams *RunParameters, positional []string) error {
+	switch parsedParams.Command {
+	case "":
+		// The command lines without a command work like before the subcommands were introduced
+		switch {
+		case parsedParams.Undo != "":
+			parsedParams.Command = CommandUndo
+		case parsedParams.Dedupe:
+			parsedParams.Command = CommandDedupe
+		default:
+			parsedParams.Command = CommandReport
+		}
+	case CommandUndo:
+		if len(positional) != 1 {
+			return fmt.Errorf("undo command requires exactly one journal path")
+		}
+
+		parsedParams.Undo = positional[0]
+		return nil
+	case CommandDedupe:
+		parsedParams.Dedupe = true
+	case CommandVerify:
+		parsedParams.Verify = true
+	}
+
+	parsedParams.Paths = append(parsedParams.Paths, positional...)
+	return nil
+}
+
+// isFlagSet checks if the flag was passed explicitly
+func isFlagSet(flagSet *flag.FlagSet, name string) bool {
+	found := false
+	flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+
+	return found
+}
+
 func (p *runParametersParser) IsParsed() bool {
 	return p.parsedParams != nil
 }
 
+// Usage shows the usage of the command passed to the last Parse call, or the list of commands
 func (p *runParametersParser) Usage() {
-	flagSet, _ := p.initFlagSet("gofilechecker")
-	flagSet.Usage()
+	out := os.Stderr
+	flagSet, _ := p.initFlagSet(p.command)
+	flagSet.SetOutput(out)
+	if p.command != "" {
+		usage := commandUsages[p.command]
+		fmt.Fprintf(out, "Usage: %s %s [flags] %s\n\n%s.\n\nFlags:\n", p.program, p.command, usage.arguments, usage.description)
+		flagSet.PrintDefaults()
+		return
+	}
+
+	fmt.Fprintf(out, "Usage: %s COMMAND [flags] [PATH...]\n\nCommands:\n", p.program)
+	for _, command := range commands {
+		fmt.Fprintf(out, "  %-8s %s\n", command, commandUsages[command].description)
+	}
+
+	fmt.Fprintf(out, "\nRun \"%s COMMAND -h\" to see the command flags.\n", p.program)
+	fmt.Fprintf(out, "Without a command the paths are reported, and the flags of all commands are accepted:\n")
+	flagSet.PrintDefaults()
 }
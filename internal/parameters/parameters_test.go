@@ -2,11 +2,42 @@ package parameters
 
 import (
 	"os"
+	"runtime"
 	"testing"
+	"time"
 
+	"github.com/pryazhnikov/gofileschecker/internal/actions"
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/report"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// newTestParameters returns the parameters parsed from a single path flag with the overridden fields
+func newTestParameters(override func(p *RunParameters)) *RunParameters {
+	params := &RunParameters{
+		Command:       CommandReport,
+		Paths:         []string{"/test/path"},
+		Workers:       runtime.NumCPU(),
+		PartialKB:     4,
+		HashAlgorithm: checkers.HashSHA256,
+		Format:        report.FormatText,
+		LogFormat:     LogFormatJSON,
+		KeepPolicy:    actions.KeepOldest,
+		DedupeMode:    actions.DedupeRemove,
+		LinkStyle:     actions.LinkRelative,
+		DryRun:        true,
+		ErrorPolicy:   scanner.ErrorPolicyContinue,
+		MaxErrors:     10,
+	}
+	if override != nil {
+		override(params)
+	}
+
+	return params
+}
+
 func TestParseParameters(t *testing.T) {
 	// Save original args and restore them after the test
 	originalArgs := os.Args
@@ -35,14 +66,244 @@ func TestParseParameters(t *testing.T) {
 		{
 			name: "valid parameters",
 			args: []string{"prog", "-path", "/test/path", "-debug", "-fullpath", "-skipempty"},
-			want: &RunParameters{
-				Paths:          []string{"/test/path"},
-				Debug:          true,
-				FullFilePath:   true,
-				SkipEmptyFiles: true,
+			want: newTestParameters(func(p *RunParameters) {
+				p.Debug = true
+				p.FullFilePath = true
+				p.SkipEmptyFiles = true
+				p.MinSize = 1
+			}),
+			wantErr: false,
+		},
+		{
+			name: "check and output parameters",
+			args: []string{
+				"prog", "-path", "/test/path", "-workers", "3", "-partial-kb", "16", "-verify", "-hash", "xxh64",
+				"-format", "json", "-logfile", "/tmp/test.log", "-log-format", "console", "-quiet",
+			},
+			want: newTestParameters(func(p *RunParameters) {
+				p.Workers = 3
+				p.PartialKB = 16
+				p.Verify = true
+				p.HashAlgorithm = checkers.HashXXH64
+				p.Format = report.FormatJSON
+				p.LogFile = "/tmp/test.log"
+				p.LogFormat = LogFormatConsole
+				p.Quiet = true
+			}),
+			wantErr: false,
+		},
+		{
+			name: "abort after errors limit",
+			args: []string{"prog", "-path", "/test/path", "-on-error", "abort-after", "-max-errors", "5"},
+			want: newTestParameters(func(p *RunParameters) {
+				p.ErrorPolicy = scanner.ErrorPolicyAbortAfterN
+				p.MaxErrors = 5
+			}),
+			wantErr: false,
+		},
+		{
+			name: "file size range",
+			args: []string{"prog", "-path", "/test/path", "-min-size", "10K", "-max-size", "2G"},
+			want: newTestParameters(func(p *RunParameters) {
+				p.MinSize = 10 * 1024
+				p.MaxSize = 2 * 1024 * 1024 * 1024
+			}),
+			wantErr: false,
+		},
+		{
+			name: "modification time range",
+			args: []string{"prog", "-path", "/test/path", "-newer-than", "2024-01-31", "-older-than", "7d"},
+			want: newTestParameters(func(p *RunParameters) {
+				p.NewerThan = time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)
+				p.OlderThan = time.Now().Add(-7 * 24 * time.Hour)
+			}),
+			wantErr: false,
+		},
+		{
+			name:    "empty modification time range",
+			args:    []string{"prog", "-path", "/test/path", "-newer-than", "7d", "-older-than", "30d"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid modification time",
+			args:    []string{"prog", "-path", "/test/path", "-newer-than", "yesterday"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "min size exceeds max size",
+			args:    []string{"prog", "-path", "/test/path", "-min-size", "4M", "-max-size", "1M"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid size",
+			args:    []string{"prog", "-path", "/test/path", "-max-size", "4X"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "negative partial hash size",
+			args:    []string{"prog", "-path", "/test/path", "-partial-kb", "-1"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown hash algorithm",
+			args:    []string{"prog", "-path", "/test/path", "-hash", "sha3"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown report format",
+			args:    []string{"prog", "-path", "/test/path", "-format", "xml"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown log format",
+			args:    []string{"prog", "-path", "/test/path", "-log-format", "xml"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "dedupe with confirmation",
+			args: []string{
+				"prog", "-path", "/test/path", "-dedupe", "-dedupe-mode", "symlink", "-link-style", "absolute",
+				"-keep", "regex", "-keep-regex", "^/test/", "-dry-run=false", "-confirm", "-journal", "/test/journal.ndjson",
 			},
+			want: newTestParameters(func(p *RunParameters) {
+				p.Command = CommandDedupe
+				p.Dedupe = true
+				p.DedupeMode = actions.DedupeSymlink
+				p.LinkStyle = actions.LinkAbsolute
+				p.KeepPolicy = actions.KeepRegex
+				p.KeepRegex = "^/test/"
+				p.DryRun = false
+				p.Confirm = true
+				p.Journal = "/test/journal.ndjson"
+				p.Verify = true
+			}),
 			wantErr: false,
 		},
+		{
+			name: "quarantine implies dedupe",
+			args: []string{"prog", "-path", "/test/path", "-quarantine", "/test/quarantine"},
+			want: newTestParameters(func(p *RunParameters) {
+				p.Command = CommandDedupe
+				p.Dedupe = true
+				p.DedupeMode = actions.DedupeQuarantine
+				p.Quarantine = "/test/quarantine"
+			}),
+			wantErr: false,
+		},
+		{
+			name: "undo without paths",
+			args: []string{"prog", "-undo", "/test/journal.ndjson"},
+			want: newTestParameters(func(p *RunParameters) {
+				p.Command = CommandUndo
+				p.Paths = []string{}
+				p.Undo = "/test/journal.ndjson"
+			}),
+			wantErr: false,
+		},
+		{
+			name: "script implies dedupe",
+			args: []string{"prog", "-path", "/test/path", "-script", "/test/dedupe.sh", "-dedupe-mode", "hardlink", "-dry-run=false"},
+			want: newTestParameters(func(p *RunParameters) {
+				p.Command = CommandDedupe
+				p.Dedupe = true
+				p.DedupeMode = actions.DedupeHardlink
+				p.DryRun = false
+				p.Script = "/test/dedupe.sh"
+			}),
+			wantErr: false,
+		},
+		{
+			name: "interactive review applies changes after confirmation",
+			args: []string{"prog", "-path", "/test/path", "-interactive"},
+			want: newTestParameters(func(p *RunParameters) {
+				p.Command = CommandDedupe
+				p.Dedupe = true
+				p.Interactive = true
+				p.Verify = true
+				p.DryRun = false
+			}),
+			wantErr: false,
+		},
+		{
+			name:    "interactive review with script",
+			args:    []string{"prog", "-path", "/test/path", "-interactive", "-script", "/test/dedupe.sh"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "quarantine with another dedupe mode",
+			args:    []string{"prog", "-path", "/test/path", "-dedupe-mode", "hardlink", "-quarantine", "/test/quarantine"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "quarantine dedupe mode without directory",
+			args:    []string{"prog", "-path", "/test/path", "-dedupe", "-dedupe-mode", "quarantine"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "dedupe without confirmation",
+			args:    []string{"prog", "-path", "/test/path", "-dedupe", "-dry-run=false"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "dedupe without verification",
+			args:    []string{"prog", "-path", "/test/path", "-dedupe", "-dry-run=false", "-confirm", "-verify=false"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "regex keep policy without pattern",
+			args:    []string{"prog", "-path", "/test/path", "-dedupe", "-keep", "regex"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown dedupe mode",
+			args:    []string{"prog", "-path", "/test/path", "-dedupe", "-dedupe-mode", "move"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown link style",
+			args:    []string{"prog", "-path", "/test/path", "-link-style", "hard"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown keep policy",
+			args:    []string{"prog", "-path", "/test/path", "-keep", "random"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown error policy",
+			args:    []string{"prog", "-path", "/test/path", "-on-error", "ignore"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "non-positive errors limit",
+			args:    []string{"prog", "-path", "/test/path", "-on-error", "abort-after", "-max-errors", "0"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "zero workers",
+			args:    []string{"prog", "-path", "/test/path", "-workers", "0"},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "missing path parameter",
 			args:    []string{"prog"},
@@ -50,25 +311,17 @@ func TestParseParameters(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "only path parameter",
-			args: []string{"prog", "-path", "/test/path"},
-			want: &RunParameters{
-				Paths:          []string{"/test/path"},
-				Debug:          false,
-				FullFilePath:   false,
-				SkipEmptyFiles: false,
-			},
+			name:    "only path parameter",
+			args:    []string{"prog", "-path", "/test/path"},
+			want:    newTestParameters(nil),
 			wantErr: false,
 		},
 		{
 			name: "multiple path parameters",
 			args: []string{"prog", "-path", "/test/path1", "-path", "/test/path2"},
-			want: &RunParameters{
-				Paths:          []string{"/test/path1", "/test/path2"},
-				Debug:          false,
-				FullFilePath:   false,
-				SkipEmptyFiles: false,
-			},
+			want: newTestParameters(func(p *RunParameters) {
+				p.Paths = []string{"/test/path1", "/test/path2"}
+			}),
 			wantErr: false,
 		},
 	}
@@ -83,11 +336,256 @@ func TestParseParameters(t *testing.T) {
 			}
 
 			assert.NoError(t, err, "parseParameters() should not return an error")
+			assert.Equal(t, tt.want.Command, got.Command, "wrong command")
 			assert.Equal(t, tt.want.Paths, got.Paths, "wrong paths value")
 			assert.Equal(t, tt.want.Debug, got.Debug, "wrong value of debug flag")
 			assert.Equal(t, tt.want.FullFilePath, got.FullFilePath, "wrong value of fullFilePath flag")
 			assert.Equal(t, tt.want.SkipEmptyFiles, got.SkipEmptyFiles, "wrong value of skipEmptyFiles flag")
+			assert.Equal(t, tt.want.MinSize, got.MinSize, "wrong value of min-size flag")
+			assert.Equal(t, tt.want.MaxSize, got.MaxSize, "wrong value of max-size flag")
+			assert.WithinDuration(t, tt.want.NewerThan, got.NewerThan, time.Minute, "wrong value of newer-than flag")
+			assert.WithinDuration(t, tt.want.OlderThan, got.OlderThan, time.Minute, "wrong value of older-than flag")
+			assert.Equal(t, tt.want.Workers, got.Workers, "wrong value of workers flag")
+			assert.Equal(t, tt.want.Verify, got.Verify, "wrong value of verify flag")
+			assert.Equal(t, tt.want.HashAlgorithm, got.HashAlgorithm, "wrong value of hash flag")
+			assert.Equal(t, tt.want.Format, got.Format, "wrong value of format flag")
+			assert.Equal(t, tt.want.LogFile, got.LogFile, "wrong value of logfile flag")
+			assert.Equal(t, tt.want.LogFormat, got.LogFormat, "wrong value of log-format flag")
+			assert.Equal(t, tt.want.Quiet, got.Quiet, "wrong value of quiet flag")
+			assert.Equal(t, tt.want.Dedupe, got.Dedupe, "wrong value of dedupe flag")
+			assert.Equal(t, tt.want.DedupeMode, got.DedupeMode, "wrong value of dedupe-mode flag")
+			assert.Equal(t, tt.want.LinkStyle, got.LinkStyle, "wrong value of link-style flag")
+			assert.Equal(t, tt.want.KeepPolicy, got.KeepPolicy, "wrong value of keep flag")
+			assert.Equal(t, tt.want.KeepRegex, got.KeepRegex, "wrong value of keep-regex flag")
+			assert.Equal(t, tt.want.DryRun, got.DryRun, "wrong value of dry-run flag")
+			assert.Equal(t, tt.want.Confirm, got.Confirm, "wrong value of confirm flag")
+			assert.Equal(t, tt.want.Quarantine, got.Quarantine, "wrong value of quarantine flag")
+			assert.Equal(t, tt.want.Journal, got.Journal, "wrong value of journal flag")
+			assert.Equal(t, tt.want.Script, got.Script, "wrong value of script flag")
+			assert.Equal(t, tt.want.Interactive, got.Interactive, "wrong value of interactive flag")
+			assert.Equal(t, tt.want.Undo, got.Undo, "wrong value of undo flag")
+			assert.Equal(t, tt.want.Listen, got.Listen, "wrong value of listen flag")
+			assert.Equal(t, tt.want.PartialKB, got.PartialKB, "wrong value of partialKB flag")
+			assert.Equal(t, tt.want.ErrorPolicy, got.ErrorPolicy, "wrong value of onError flag")
+			assert.Equal(t, tt.want.MaxErrors, got.MaxErrors, "wrong value of maxErrors flag")
 		})
 	}
 }
 
+func TestParseCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    *RunParameters // Only the fields depending on the command are compared
+		wantErr bool
+	}{
+		{
+			name: "scan with positional paths",
+			args: []string{"prog", "scan", "/test/path1", "/test/path2"},
+			want: &RunParameters{Command: CommandScan, Paths: []string{"/test/path1", "/test/path2"}, Format: report.FormatText},
+		},
+		{
+			name: "report with path flag and positional path",
+			args: []string{"prog", "report", "-path", "/test/path1", "/test/path2", "-format", "csv"},
+			want: &RunParameters{Command: CommandReport, Paths: []string{"/test/path1", "/test/path2"}, Format: report.FormatCSV},
+		},
+		{
+			name: "positional paths after terminator",
+			args: []string{"prog", "report", "--", "-format", "/test/path"},
+			want: &RunParameters{Command: CommandReport, Paths: []string{"-format", "/test/path"}, Format: report.FormatText},
+		},
+		{
+			name: "verify compares files byte-by-byte",
+			args: []string{"prog", "verify", "/test/path"},
+			want: &RunParameters{Command: CommandVerify, Paths: []string{"/test/path"}, Format: report.FormatText, Verify: true},
+		},
+		{
+			name: "dedupe",
+			args: []string{"prog", "dedupe", "-dedupe-mode", "hardlink", "/test/path"},
+			want: &RunParameters{
+				Command:    CommandDedupe,
+				Paths:      []string{"/test/path"},
+				Format:     report.FormatText,
+				Dedupe:     true,
+				DedupeMode: actions.DedupeHardlink,
+			},
+		},
+		{
+			name: "compare",
+			args: []string{"prog", "compare", "/test/path1", "/test/path2"},
+			want: &RunParameters{Command: CommandCompare, Paths: []string{"/test/path1", "/test/path2"}, Format: report.FormatText},
+		},
+		{
+			name: "scan with include and exclude patterns",
+			args: []string{"prog", "scan", "-exclude", "node_modules", "-include", "*.jpg", "-exclude", "**/*.tmp", "/test/path"},
+			want: &RunParameters{
+				Command: CommandScan,
+				Paths:   []string{"/test/path"},
+				Format:  report.FormatText,
+				Include: []string{"*.jpg"},
+				Exclude: []string{"node_modules", "**/*.tmp"},
+			},
+		},
+		{
+			name: "report honoring ignore files",
+			args: []string{"prog", "report", "-ignore-files", "/test/path"},
+			want: &RunParameters{
+				Command:     CommandReport,
+				Paths:       []string{"/test/path"},
+				Format:      report.FormatText,
+				IgnoreFiles: true,
+			},
+		},
+		{
+			name: "scan following symlinks",
+			args: []string{"prog", "scan", "-symlinks", "follow", "/test/path"},
+			want: &RunParameters{
+				Command:  CommandScan,
+				Paths:    []string{"/test/path"},
+				Format:   report.FormatText,
+				Symlinks: scanner.SymlinkFollow,
+			},
+		},
+		{
+			name:    "unknown symlinks policy",
+			args:    []string{"prog", "scan", "-symlinks", "ignore", "/test/path"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid exclude pattern",
+			args:    []string{"prog", "scan", "-exclude", "[a-", "/test/path"},
+			wantErr: true,
+		},
+		{
+			name:    "compare single path",
+			args:    []string{"prog", "compare", "/test/path"},
+			wantErr: true,
+		},
+		{
+			name: "serve",
+			args: []string{"prog", "serve", "-listen", ":9000", "/test/path"},
+			want: &RunParameters{Command: CommandServe, Paths: []string{"/test/path"}, Format: report.FormatText, Listen: ":9000"},
+		},
+		{
+			name: "undo",
+			args: []string{"prog", "undo", "/test/journal.ndjson"},
+			want: &RunParameters{Command: CommandUndo, Paths: []string{}, Format: report.FormatText, Undo: "/test/journal.ndjson"},
+		},
+		{
+			name:    "undo without journal",
+			args:    []string{"prog", "undo"},
+			wantErr: true,
+		},
+		{
+			name:    "flag of another command",
+			args:    []string{"prog", "scan", "-dedupe-mode", "hardlink", "/test/path"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown command",
+			args:    []string{"prog", "purge", "/test/path"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRunParametersParser().Parse(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err, "An error is expected")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.Command, got.Command, "wrong command")
+			assert.Equal(t, tt.want.Paths, got.Paths, "wrong paths value")
+			assert.Equal(t, tt.want.Format, got.Format, "wrong value of format flag")
+			assert.Equal(t, tt.want.Verify, got.Verify, "wrong value of verify flag")
+			assert.Equal(t, tt.want.Dedupe, got.Dedupe, "wrong value of dedupe flag")
+			if tt.want.DedupeMode != "" {
+				assert.Equal(t, tt.want.DedupeMode, got.DedupeMode, "wrong value of dedupe-mode flag")
+			}
+			assert.Equal(t, tt.want.Listen, got.Listen, "wrong value of listen flag")
+			assert.Equal(t, tt.want.Undo, got.Undo, "wrong journal path")
+			assert.Equal(t, tt.want.Include, got.Include, "wrong include patterns")
+			assert.Equal(t, tt.want.Exclude, got.Exclude, "wrong exclude patterns")
+			assert.Equal(t, tt.want.IgnoreFiles, got.IgnoreFiles, "wrong value of ignore-files flag")
+			assert.Equal(t, tt.want.Symlinks, got.Symlinks, "wrong value of symlinks flag")
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "0", want: 0},
+		{value: "512", want: 512},
+		{value: "512B", want: 512},
+		{value: "10K", want: 10 * 1024},
+		{value: "10k", want: 10 * 1024},
+		{value: "4M", want: 4 * 1024 * 1024},
+		{value: "4MiB", want: 4 * 1024 * 1024},
+		{value: "2GB", want: 2 * 1024 * 1024 * 1024},
+		{value: "1T", want: 1024 * 1024 * 1024 * 1024},
+		{value: "", wantErr: true},
+		{value: "K", wantErr: true},
+		{value: "-1K", wantErr: true},
+		{value: "1.5G", wantErr: true},
+		{value: "10X", wantErr: true},
+		{value: "10KK", wantErr: true},
+		{value: "9999999999T", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSize(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err, "An error is expected")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseTimeLimit(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2024-01-31", want: time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)},
+		{value: "2024-01-31 08:30:00", want: time.Date(2024, 1, 31, 8, 30, 0, 0, time.Local)},
+		{value: "2024-01-31T08:30:00Z", want: time.Date(2024, 1, 31, 8, 30, 0, 0, time.UTC)},
+		{value: "30d", want: now.AddDate(0, 0, -30)},
+		{value: "2w", want: now.AddDate(0, 0, -14)},
+		{value: "12h", want: now.Add(-12 * time.Hour)},
+		{value: "1h30m", want: now.Add(-90 * time.Minute)},
+		{value: "0d", want: now},
+		{value: "", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "1y", wantErr: true},
+		{value: "999999d", wantErr: true},
+		{value: "2024-13-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTimeLimit(tt.value, now)
+			if tt.wantErr {
+				assert.Error(t, err, "An error is expected")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "expected %s, got %s", tt.want, got)
+		})
+	}
+}
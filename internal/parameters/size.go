@@ -0,0 +1,44 @@
+package parameters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits are the multipliers of the size suffixes, the units are binary
+var sizeUnits = map[string]int64{
+	"":  1,
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+}
+
+// ParseSize parses the size in bytes with an optional unit suffix, e.g. 512, 10K, 4MiB or 2GB
+func ParseSize(value string) (int64, error) {
+	normalized := strings.TrimSpace(strings.ToUpper(value))
+	if strings.HasSuffix(normalized, "IB") {
+		normalized = strings.TrimSuffix(normalized, "IB")
+	} else {
+		normalized = strings.TrimSuffix(normalized, "B")
+	}
+
+	digits := strings.TrimRight(normalized, "KMGT")
+	unit := normalized[len(digits):]
+	multiplier, ok := sizeUnits[unit]
+	if !ok || digits == "" {
+		return 0, fmt.Errorf("invalid size: %q", value)
+	}
+
+	size, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size: %q", value)
+	}
+
+	if size > (1<<63-1)/multiplier {
+		return 0, fmt.Errorf("size is too large: %q", value)
+	}
+
+	return size * multiplier, nil
+}
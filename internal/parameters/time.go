@@ -0,0 +1,65 @@
+package parameters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts are the accepted formats of the absolute time, the time without a zone is local
+var timeLayouts = []string{time.RFC3339, time.DateTime, "2006-01-02T15:04:05", time.DateOnly}
+
+// dayUnits are the duration suffixes which are not supported by time.ParseDuration
+var dayUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ParseTimeLimit parses the absolute time (e.g. 2024-01-31 or 2024-01-31 12:00:00)
+// or the duration before now (e.g. 30d, 2w or 12h)
+func ParseTimeLimit(value string, now time.Time) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+
+	duration, err := parseAge(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time or duration: %q", value)
+	}
+
+	return now.Add(-duration), nil
+}
+
+const maxDuration = time.Duration(1<<63 - 1)
+
+// parseAge parses the non-negative duration, days and weeks are allowed besides the time.ParseDuration units
+func parseAge(value string) (time.Duration, error) {
+	for suffix, unit := range dayUnits {
+		if count, found := strings.CutSuffix(value, suffix); found {
+			units, err := strconv.ParseInt(count, 10, 64)
+			if err != nil {
+				return 0, err
+			}
+
+			if units < 0 || units > int64(maxDuration/unit) {
+				return 0, fmt.Errorf("duration is out of range: %s", value)
+			}
+
+			return time.Duration(units) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("negative duration: %s", value)
+	}
+
+	return duration, nil
+}
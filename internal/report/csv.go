@@ -0,0 +1,73 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
+)
+
+var csvHeader = []string{"group_id", "hash", "size", "path", "relative_path", "mtime", "wasted_bytes"}
+
+// csvWriter outputs one row per duplicated file. The first file of every group is counted as the original one,
+// all the others are counted as wasted space. Failures and the summary are not included, they are logged.
+type csvWriter struct {
+	writer  *csv.Writer
+	groupID int
+}
+
+func newCSVWriter(out io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(out)}
+}
+
+func (w *csvWriter) Start(info ScanInfo) error {
+	// WriteAll flushes the rows right away, so every group is available as soon as it is written
+	return w.writer.WriteAll([][]string{csvHeader})
+}
+
+func (w *csvWriter) Progress(progress scanner.Progress) error {
+	return nil // There is no place for the progress in the table
+}
+
+func (w *csvWriter) WriteGroup(group *checkers.FilesCheckGroup) error {
+	w.groupID++
+
+	pathPrefix := group.CommonPathPrefix()
+	var rows [][]string
+	for idx, path := range group.Files() {
+		details := describeFile(path)
+
+		size := group.Size()
+		mtime := ""
+		if details.Err == nil {
+			size = details.Size
+			mtime = details.ModTime.Format(time.RFC3339)
+		}
+
+		var wasted int64
+		if idx > 0 {
+			wasted = size
+		}
+
+		rows = append(rows, []string{
+			strconv.Itoa(w.groupID),
+			group.Hash(),
+			strconv.FormatInt(size, 10),
+			path,
+			strings.TrimPrefix(path, pathPrefix),
+			mtime,
+			strconv.FormatInt(wasted, 10),
+		})
+	}
+
+	return w.writer.WriteAll(rows)
+}
+
+func (w *csvWriter) Finish(summary Summary) error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+)
+
+var contentTypes = map[Format]string{
+	FormatText:   "text/plain; charset=utf-8",
+	FormatJSON:   "application/json",
+	FormatNDJSON: "application/x-ndjson",
+	FormatCSV:    "text/csv; charset=utf-8",
+}
+
+// NewHandler serves the report of the completed scan at /report.
+// The format is chosen by the format query parameter, JSON is used by default.
+func NewHandler(info ScanInfo, groups []*checkers.FilesCheckGroup, summary Summary, options WriterOptions) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		format := FormatJSON
+		if name := r.URL.Query().Get("format"); name != "" {
+			parsed, err := ParseFormat(name)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			format = parsed
+		}
+
+		// The report is rendered completely before sending to respond with an error status on failure
+		var body bytes.Buffer
+		if err := writeReport(&body, format, info, groups, summary, options); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.Write(body.Bytes())
+	})
+
+	return mux
+}
+
+func writeReport(out *bytes.Buffer, format Format, info ScanInfo, groups []*checkers.FilesCheckGroup, summary Summary, options WriterOptions) error {
+	writer, err := NewWriter(format, out, options)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Start(info); err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if err := writer.WriteGroup(group); err != nil {
+			return err
+		}
+	}
+
+	return writer.Finish(summary)
+}
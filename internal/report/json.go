@@ -0,0 +1,202 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
+)
+
+type jsonScan struct {
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at,omitzero"`
+	Paths         []string  `json:"paths"`
+	HashAlgorithm string    `json:"hash_algorithm"`
+	Verified      bool      `json:"verified"`
+}
+
+type jsonSummary struct {
+	Files              int   `json:"files"`
+	Directories        int   `json:"directories"`
+	Errors             int   `json:"errors"`
+	Skipped            int   `json:"skipped"`
+	RegisteredFiles    int   `json:"registered_files"`
+	UniqueSizeFiles    int   `json:"unique_size_files"`
+	PartialHashedFiles int   `json:"partial_hashed_files"`
+	PartialHashedBytes int64 `json:"partial_hashed_bytes"`
+	HashedFiles        int   `json:"hashed_files"`
+	HashedBytes        int64 `json:"hashed_bytes"`
+	SavedBytes         int64 `json:"saved_bytes"`
+}
+
+type jsonFailure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+type jsonSymlink struct {
+	Path   string `json:"path"`
+	Target string `json:"target"`
+}
+
+type jsonVerificationIssue struct {
+	Hash      string `json:"hash"`
+	Path      string `json:"path"`
+	Reference string `json:"reference,omitempty"`
+	Error     string `json:"error"`
+}
+
+type jsonFile struct {
+	Path    string     `json:"path"`
+	Size    *int64     `json:"size,omitempty"`
+	ModTime *time.Time `json:"mtime,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+type jsonGroup struct {
+	Hash         string     `json:"hash"`
+	Algorithm    string     `json:"algorithm"`
+	Size         int64      `json:"size"`
+	CommonPrefix string     `json:"common_prefix"`
+	Files        []jsonFile `json:"files"`
+}
+
+type jsonReport struct {
+	SchemaVersion      int                     `json:"schema_version"`
+	Scan               jsonScan                `json:"scan"`
+	Summary            jsonSummary             `json:"summary"`
+	Failures           []jsonFailure           `json:"failures"`
+	VerificationIssues []jsonVerificationIssue `json:"verification_issues"`
+	Symlinks           []jsonSymlink           `json:"symlinks,omitempty"`
+	Groups             []jsonGroup             `json:"groups"`
+	Error              string                  `json:"error,omitempty"`
+}
+
+// jsonWriter outputs the whole report as a single JSON document on Finish
+type jsonWriter struct {
+	out    io.Writer
+	report jsonReport
+}
+
+func newJSONWriter(out io.Writer) *jsonWriter {
+	return &jsonWriter{
+		out: out,
+		report: jsonReport{
+			SchemaVersion: SchemaVersion,
+			Groups:        []jsonGroup{},
+		},
+	}
+}
+
+func (w *jsonWriter) Start(info ScanInfo) error {
+	w.report.Scan = jsonScan{
+		StartedAt:     info.StartedAt,
+		Paths:         info.Paths,
+		HashAlgorithm: string(info.HashAlgorithm),
+		Verified:      info.Verify,
+	}
+
+	return nil
+}
+
+func (w *jsonWriter) Progress(progress scanner.Progress) error {
+	return nil // The document is written at once, there is no place for the progress
+}
+
+func (w *jsonWriter) WriteGroup(group *checkers.FilesCheckGroup) error {
+	w.report.Groups = append(w.report.Groups, newJSONGroup(group))
+	return nil
+}
+
+func (w *jsonWriter) Finish(summary Summary) error {
+	w.report.Scan.FinishedAt = summary.FinishedAt
+	w.report.Summary = newJSONSummary(summary)
+	w.report.Failures = newJSONFailures(summary)
+	w.report.VerificationIssues = newJSONVerificationIssues(summary)
+	w.report.Symlinks = newJSONSymlinks(summary)
+	w.report.Error = errorText(summary.Err)
+
+	encoder := json.NewEncoder(w.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(w.report)
+}
+
+func newJSONSummary(summary Summary) jsonSummary {
+	return jsonSummary{
+		Files:              summary.Stats.Files(),
+		Directories:        summary.Stats.Directories(),
+		Errors:             summary.Stats.Errors(),
+		Skipped:            summary.Stats.Skipped(),
+		RegisteredFiles:    summary.CheckStats.RegisteredFiles,
+		UniqueSizeFiles:    summary.CheckStats.UniqueSizeFiles,
+		PartialHashedFiles: summary.CheckStats.PartialHashedFiles,
+		PartialHashedBytes: summary.CheckStats.PartialHashedBytes,
+		HashedFiles:        summary.CheckStats.HashedFiles,
+		HashedBytes:        summary.CheckStats.HashedBytes,
+		SavedBytes:         summary.CheckStats.SavedBytes,
+	}
+}
+
+func newJSONSymlinks(summary Summary) []jsonSymlink {
+	var result []jsonSymlink
+	for _, link := range summary.Symlinks {
+		result = append(result, jsonSymlink{Path: link.Path, Target: link.Target})
+	}
+
+	return result
+}
+
+func newJSONFailures(summary Summary) []jsonFailure {
+	result := []jsonFailure{}
+	for _, failure := range summary.Failures {
+		result = append(result, jsonFailure{Path: failure.Path, Error: failure.Err.Error()})
+	}
+
+	return result
+}
+
+func newJSONVerificationIssues(summary Summary) []jsonVerificationIssue {
+	result := []jsonVerificationIssue{}
+	for _, issue := range summary.VerificationIssues {
+		result = append(result, jsonVerificationIssue{
+			Hash:      issue.Hash,
+			Path:      issue.Path,
+			Reference: issue.Reference,
+			Error:     issue.Err.Error(),
+		})
+	}
+
+	return result
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+func newJSONGroup(group *checkers.FilesCheckGroup) jsonGroup {
+	result := jsonGroup{
+		Hash:         group.Hash(),
+		Algorithm:    string(group.Algorithm()),
+		Size:         group.Size(),
+		CommonPrefix: group.CommonPathPrefix(),
+		Files:        []jsonFile{},
+	}
+
+	for _, path := range group.Files() {
+		details := describeFile(path)
+		if details.Err != nil {
+			result.Files = append(result.Files, jsonFile{Path: path, Error: details.Err.Error()})
+			continue
+		}
+
+		result.Files = append(result.Files, jsonFile{Path: path, Size: &details.Size, ModTime: &details.ModTime})
+	}
+
+	return result
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
+)
+
+// NDJSON event types, every output line has one of them in the "event" field
+const (
+	eventScanStarted = "scan-started"
+	eventProgress    = "progress"
+	eventGroup       = "group"
+	eventSummary     = "summary"
+)
+
+type ndjsonEvent struct {
+	SchemaVersion int       `json:"schema_version"`
+	Event         string    `json:"event"`
+	Time          time.Time `json:"time"`
+}
+
+type ndjsonScanStarted struct {
+	ndjsonEvent
+	Scan jsonScan `json:"scan"`
+}
+
+type ndjsonProgress struct {
+	ndjsonEvent
+	Phase       string `json:"phase"`
+	Files       int    `json:"files"`
+	Directories int    `json:"directories"`
+	Errors      int    `json:"errors"`
+	Skipped     int    `json:"skipped"`
+	Checked     int    `json:"checked"`
+	Candidates  int    `json:"candidates"`
+}
+
+type ndjsonGroup struct {
+	ndjsonEvent
+	Group jsonGroup `json:"group"`
+}
+
+type ndjsonSummary struct {
+	ndjsonEvent
+	FinishedAt         time.Time               `json:"finished_at"`
+	Summary            jsonSummary             `json:"summary"`
+	Failures           []jsonFailure           `json:"failures"`
+	VerificationIssues []jsonVerificationIssue `json:"verification_issues"`
+	Symlinks           []jsonSymlink           `json:"symlinks,omitempty"`
+	Error              string                  `json:"error,omitempty"`
+}
+
+// ndjsonWriter streams every event as a separate JSON line as soon as it happens
+type ndjsonWriter struct {
+	mu      sync.Mutex // Progress events can come from multiple goroutines
+	encoder *json.Encoder
+	now     func() time.Time
+}
+
+func newNDJSONWriter(out io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{encoder: json.NewEncoder(out), now: time.Now}
+}
+
+func (w *ndjsonWriter) newEvent(event string) ndjsonEvent {
+	return ndjsonEvent{SchemaVersion: SchemaVersion, Event: event, Time: w.now()}
+}
+
+func (w *ndjsonWriter) write(line any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.encoder.Encode(line) // Encode adds the line break
+}
+
+func (w *ndjsonWriter) Start(info ScanInfo) error {
+	return w.write(ndjsonScanStarted{
+		ndjsonEvent: w.newEvent(eventScanStarted),
+		Scan: jsonScan{
+			StartedAt:     info.StartedAt,
+			Paths:         info.Paths,
+			HashAlgorithm: string(info.HashAlgorithm),
+			Verified:      info.Verify,
+		},
+	})
+}
+
+func (w *ndjsonWriter) Progress(progress scanner.Progress) error {
+	return w.write(ndjsonProgress{
+		ndjsonEvent: w.newEvent(eventProgress),
+		Phase:       string(progress.Phase),
+		Files:       progress.Stats.Files(),
+		Directories: progress.Stats.Directories(),
+		Errors:      progress.Stats.Errors(),
+		Skipped:     progress.Stats.Skipped(),
+		Checked:     progress.Checked,
+		Candidates:  progress.Candidates,
+	})
+}
+
+func (w *ndjsonWriter) WriteGroup(group *checkers.FilesCheckGroup) error {
+	return w.write(ndjsonGroup{
+		ndjsonEvent: w.newEvent(eventGroup),
+		Group:       newJSONGroup(group),
+	})
+}
+
+func (w *ndjsonWriter) Finish(summary Summary) error {
+	return w.write(ndjsonSummary{
+		ndjsonEvent:        w.newEvent(eventSummary),
+		FinishedAt:         summary.FinishedAt,
+		Summary:            newJSONSummary(summary),
+		Failures:           newJSONFailures(summary),
+		VerificationIssues: newJSONVerificationIssues(summary),
+		Symlinks:           newJSONSymlinks(summary),
+		Error:              errorText(summary.Err),
+	})
+}
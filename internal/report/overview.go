@@ -0,0 +1,46 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+)
+
+// WriteOverview outputs the scan statistics and the totals of the duplicated groups without listing the files
+func WriteOverview(out io.Writer, summary Summary, groups []*checkers.FilesCheckGroup) error {
+	var duplicates int
+	var wastedBytes int64
+	for _, group := range groups {
+		duplicates += group.FilesCount() - 1
+		wastedBytes += int64(group.FilesCount()-1) * group.Size()
+	}
+
+	_, err := fmt.Fprintf(
+		out,
+		"Directories: %d, files: %d, errors: %d, skipped: %d\n"+
+			"Duplicated groups: %d, duplicates: %d, wasted bytes: %d\n",
+		summary.Stats.Directories(),
+		summary.Stats.Files(),
+		summary.Stats.Errors(),
+		summary.Stats.Skipped(),
+		len(groups),
+		duplicates,
+		wastedBytes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(summary.Symlinks) > 0 {
+		if _, err = fmt.Fprintf(out, "Symbolic links: %d\n", len(summary.Symlinks)); err != nil {
+			return err
+		}
+	}
+
+	if summary.Err != nil {
+		_, err = fmt.Fprintf(out, "Scan stopped, the results are incomplete: %v\n", summary.Err)
+	}
+
+	return err
+}
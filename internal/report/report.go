@@ -0,0 +1,108 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
+)
+
+// SchemaVersion is incremented on every incompatible change of the machine-readable report formats
+const SchemaVersion = 1
+
+// Format is a name of the report output format
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson" // Newline delimited JSON events streamed during the scan
+	FormatCSV    Format = "csv"
+)
+
+var formats = []Format{FormatText, FormatJSON, FormatNDJSON, FormatCSV}
+
+// IsStreaming checks if the format writes every group as soon as it is found.
+// The other formats get all groups sorted once the scan is completed.
+func (f Format) IsStreaming() bool {
+	return f == FormatNDJSON
+}
+
+func ParseFormat(name string) (Format, error) {
+	for _, format := range formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown report format: %q", name)
+}
+
+// ScanInfo describes the scan being reported
+type ScanInfo struct {
+	StartedAt     time.Time
+	Paths         []string
+	HashAlgorithm checkers.HashAlgorithm
+	Verify        bool
+}
+
+// Summary contains the scan results except the duplicated groups
+type Summary struct {
+	FinishedAt         time.Time
+	Stats              scanner.ScanSummaryStats
+	CheckStats         checkers.CheckStats
+	Failures           []scanner.FileError
+	VerificationIssues []checkers.VerificationIssue
+	Symlinks           []scanner.Symlink // The links found with the report symlink policy
+	Err                error             // The reason of the stopped scan, the results are incomplete if set
+}
+
+// Writer outputs the scan results. Start is called before the scan, Progress is called
+// during the scan (possibly from multiple goroutines), WriteGroup is called for every duplicated files group
+// (during the scan from multiple goroutines for the streaming formats, see Format.IsStreaming,
+// or once the scan is completed), and Finish is called at the end.
+type Writer interface {
+	Start(info ScanInfo) error
+	Progress(progress scanner.Progress) error
+	WriteGroup(group *checkers.FilesCheckGroup) error
+	Finish(summary Summary) error
+}
+
+type WriterOptions struct {
+	FullFilePath bool // Show full file paths in the text report
+}
+
+func NewWriter(format Format, out io.Writer, options WriterOptions) (Writer, error) {
+	switch format {
+	case FormatText:
+		return newTextWriter(out, options.FullFilePath), nil
+	case FormatJSON:
+		return newJSONWriter(out), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(out), nil
+	case FormatCSV:
+		return newCSVWriter(out), nil
+	}
+
+	return nil, fmt.Errorf("unknown report format: %q", string(format))
+}
+
+// fileDetails is the current state of a group member
+type fileDetails struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Err     error // The file state cannot be read
+}
+
+func describeFile(path string) fileDetails {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileDetails{Path: path, Err: err}
+	}
+
+	return fileDetails{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+}
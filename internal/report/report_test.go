@@ -0,0 +1,258 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
+	"github.com/pryazhnikov/gofileschecker/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestReport passes the groups and the summary through all the writer methods
+func writeTestReport(t *testing.T, w Writer, groups []*checkers.FilesCheckGroup, summary Summary) {
+	t.Helper()
+
+	require.NoError(t, w.Start(ScanInfo{
+		StartedAt:     time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Paths:         []string{"/test/path"},
+		HashAlgorithm: checkers.HashSHA256,
+	}))
+	require.NoError(t, w.Progress(scanner.Progress{Phase: scanner.ScanPhaseCheck, Checked: 1, Candidates: 2}))
+	for _, group := range groups {
+		require.NoError(t, w.WriteGroup(group))
+	}
+	require.NoError(t, w.Finish(summary))
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range formats {
+		parsed, err := ParseFormat(string(format))
+		require.NoError(t, err)
+		assert.Equal(t, format, parsed)
+
+		w, err := NewWriter(format, &bytes.Buffer{}, WriterOptions{})
+		require.NoError(t, err)
+		assert.NotNil(t, w)
+	}
+
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+
+	assert.True(t, FormatNDJSON.IsStreaming())
+	assert.False(t, FormatJSON.IsStreaming(), "The JSON document is written at once")
+}
+
+func TestTextWriter(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a/1.txt": "content", "b/2.txt": "content"})
+	summary := Summary{
+		Failures: []scanner.FileError{{Path: "/test/path/broken.txt", Err: errors.New("permission denied")}},
+		Symlinks: []scanner.Symlink{{Path: "/test/path/latest", Target: "a/1.txt"}},
+	}
+
+	tests := []struct {
+		name         string
+		fullFilePath bool
+		expectedFile string
+	}{
+		{name: "relative paths", fullFilePath: false, expectedFile: "- a/1.txt\n"},
+		{name: "full paths", fullFilePath: true, expectedFile: "- " + filepath.Join(dir, "a/1.txt") + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			out := &bytes.Buffer{}
+			writeTestReport(t, newTextWriter(out, tt.fullFilePath), groups, summary)
+
+			// Assert
+			text := out.String()
+			assert.Contains(t, text, "Failed to process 1 paths\n- /test/path/broken.txt: permission denied\n")
+			assert.Contains(t, text, "Found 1 symbolic links\n- /test/path/latest -> a/1.txt\n")
+			assert.Contains(t, text, "Found 1 duplicated files groups\n")
+			assert.Contains(t, text, "Location: "+dir+"/\n")
+			assert.Contains(t, text, tt.expectedFile)
+		})
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	// Arrange
+	dir, groups := testutil.NewGroups(t, map[string]string{"a/1.txt": "content", "b/2.txt": "content", "c.txt": "other"})
+	summary := Summary{
+		FinishedAt: time.Date(2025, 1, 2, 3, 5, 0, 0, time.UTC),
+		Failures:   []scanner.FileError{{Path: "/test/path/broken.txt", Err: errors.New("permission denied")}},
+		Symlinks:   []scanner.Symlink{{Path: "/test/path/latest", Target: "a/1.txt"}},
+		Err:        errors.New("scan stopped"),
+	}
+
+	// Act
+	out := &bytes.Buffer{}
+	writeTestReport(t, newJSONWriter(out), groups, summary)
+
+	// Assert
+	var decoded jsonReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded), "The report should be a single JSON document")
+
+	assert.Equal(t, SchemaVersion, decoded.SchemaVersion)
+	assert.Equal(t, []string{"/test/path"}, decoded.Scan.Paths)
+	assert.Equal(t, "sha256", decoded.Scan.HashAlgorithm)
+	assert.Equal(t, summary.FinishedAt, decoded.Scan.FinishedAt)
+	assert.Equal(t, "scan stopped", decoded.Error)
+	assert.Equal(t, []jsonFailure{{Path: "/test/path/broken.txt", Error: "permission denied"}}, decoded.Failures)
+	assert.Empty(t, decoded.VerificationIssues)
+	assert.Equal(t, []jsonSymlink{{Path: "/test/path/latest", Target: "a/1.txt"}}, decoded.Symlinks)
+
+	require.Len(t, decoded.Groups, 1)
+	group := decoded.Groups[0]
+	assert.Equal(t, groups[0].Hash(), group.Hash)
+	assert.Equal(t, "sha256", group.Algorithm)
+	assert.Equal(t, int64(len("content")), group.Size)
+	assert.Equal(t, dir+"/", group.CommonPrefix)
+
+	require.Len(t, group.Files, 2)
+	assert.Equal(t, filepath.Join(dir, "a/1.txt"), group.Files[0].Path)
+	require.NotNil(t, group.Files[0].Size)
+	assert.Equal(t, int64(len("content")), *group.Files[0].Size)
+	assert.NotNil(t, group.Files[0].ModTime)
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	// Arrange
+	_, groups := testutil.NewGroups(t, map[string]string{"1.txt": "content", "2.txt": "content", "3.txt": "x", "4.txt": "x"})
+	summary := Summary{Failures: []scanner.FileError{{Path: "/test/path/broken.txt", Err: errors.New("permission denied")}}}
+
+	// Act
+	out := &bytes.Buffer{}
+	writeTestReport(t, newNDJSONWriter(out), groups, summary)
+
+	// Assert
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 5, "scan-started, progress, two groups and summary lines are expected")
+
+	var events []string
+	for _, line := range lines {
+		var event ndjsonEvent
+		require.NoError(t, json.Unmarshal(line, &event), "Every line should be a JSON document")
+		assert.Equal(t, SchemaVersion, event.SchemaVersion)
+		events = append(events, event.Event)
+	}
+	assert.Equal(t, []string{eventScanStarted, eventProgress, eventGroup, eventGroup, eventSummary}, events)
+
+	var progress ndjsonProgress
+	require.NoError(t, json.Unmarshal(lines[1], &progress))
+	assert.Equal(t, "check", progress.Phase)
+	assert.Equal(t, 1, progress.Checked)
+	assert.Equal(t, 2, progress.Candidates)
+
+	var group ndjsonGroup
+	require.NoError(t, json.Unmarshal(lines[2], &group))
+	assert.Equal(t, groups[0].Hash(), group.Group.Hash)
+	assert.Len(t, group.Group.Files, 2)
+
+	var summaryLine ndjsonSummary
+	require.NoError(t, json.Unmarshal(lines[4], &summaryLine))
+	assert.Equal(t, []jsonFailure{{Path: "/test/path/broken.txt", Error: "permission denied"}}, summaryLine.Failures)
+}
+
+func TestCSVWriter(t *testing.T) {
+	// Arrange
+	names := []string{"a/plain.txt", "b/with,comma.txt", "c/with \"quotes\".txt", "d/with\nnewline.txt"}
+	contents := make(map[string]string)
+	for _, name := range names {
+		contents[name] = "content"
+	}
+	dir, groups := testutil.NewGroups(t, contents)
+
+	// Act
+	out := &bytes.Buffer{}
+	writeTestReport(t, newCSVWriter(out), groups, Summary{})
+
+	// Assert
+	records, err := csv.NewReader(out).ReadAll()
+	require.NoError(t, err, "The output should be a valid CSV")
+	require.Len(t, records, 1+len(names), "The header and one row per file are expected")
+	assert.Equal(t, csvHeader, records[0])
+
+	for idx, record := range records[1:] {
+		assert.Equal(t, "1", record[0], "All files belong to the same group")
+		assert.Equal(t, groups[0].Hash(), record[1])
+		assert.Equal(t, "7", record[2])
+		assert.Equal(t, filepath.Join(dir, names[idx]), record[3], "Special characters should survive escaping")
+		assert.Equal(t, names[idx], record[4])
+		assert.NotEmpty(t, record[5])
+	}
+
+	// Only the first file of the group is not wasted
+	assert.Equal(t, "0", records[1][6])
+	for _, record := range records[2:] {
+		assert.Equal(t, "7", record[6])
+	}
+}
+
+func TestWriteOverview(t *testing.T) {
+	// Arrange
+	_, groups := testutil.NewGroups(t, map[string]string{
+		"a.txt": "content",
+		"b.txt": "content",
+		"c.txt": "content",
+		"d.txt": "other",
+		"e.txt": "other",
+	})
+	var out bytes.Buffer
+
+	// Act
+	err := WriteOverview(&out, Summary{}, groups)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t,
+		"Directories: 0, files: 0, errors: 0, skipped: 0\n"+
+			"Duplicated groups: 2, duplicates: 3, wasted bytes: 19\n",
+		out.String(),
+	)
+}
+
+func TestHandler(t *testing.T) {
+	_, groups := testutil.NewGroups(t, map[string]string{"a.txt": "content", "b.txt": "content"})
+	handler := NewHandler(ScanInfo{HashAlgorithm: checkers.HashSHA256}, groups, Summary{}, WriterOptions{})
+
+	tests := []struct {
+		name                string
+		url                 string
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{name: "json by default", url: "/report", expectedStatus: http.StatusOK, expectedContentType: "application/json"},
+		{name: "csv", url: "/report?format=csv", expectedStatus: http.StatusOK, expectedContentType: "text/csv; charset=utf-8"},
+		{name: "unknown format", url: "/report?format=xml", expectedStatus: http.StatusBadRequest},
+		{name: "unknown path", url: "/other", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.Contains(t, recorder.Body.String(), groups[0].Hash())
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/scanner"
+)
+
+// textWriter outputs the human-readable report.
+// The groups are kept until Finish to print the failures before them.
+type textWriter struct {
+	out          io.Writer
+	fullFilePath bool
+	groups       []*checkers.FilesCheckGroup
+}
+
+func newTextWriter(out io.Writer, fullFilePath bool) *textWriter {
+	return &textWriter{out: out, fullFilePath: fullFilePath}
+}
+
+func (w *textWriter) Start(info ScanInfo) error {
+	return nil
+}
+
+func (w *textWriter) Progress(progress scanner.Progress) error {
+	return nil // The progress is logged by the scanner
+}
+
+func (w *textWriter) WriteGroup(group *checkers.FilesCheckGroup) error {
+	w.groups = append(w.groups, group)
+	return nil
+}
+
+func (w *textWriter) Finish(summary Summary) error {
+	w.printFailures(summary)
+	w.printVerificationIssues(summary)
+	w.printSymlinks(summary)
+
+	if len(w.groups) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w.out, "Found %d duplicated files groups\n", len(w.groups))
+	for _, fcg := range w.groups {
+		fmt.Fprintf(
+			w.out,
+			"Duplicated files group: %s (%s)\n",
+			fcg.Hash(),
+			fcg.Algorithm(),
+		)
+
+		pathPrefix := fcg.CommonPathPrefix()
+		fmt.Fprintf(w.out, "Location: %s\n", pathPrefix)
+
+		for _, file := range fcg.Files() {
+			fileView := file
+			if !w.fullFilePath {
+				fileView = strings.TrimPrefix(file, pathPrefix)
+			}
+
+			fmt.Fprintf(w.out, "- %s\n", fileView)
+		}
+
+		fmt.Fprintln(w.out)
+	}
+
+	return nil
+}
+
+func (w *textWriter) printFailures(summary Summary) {
+	if len(summary.Failures) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w.out, "Failed to process %d paths\n", len(summary.Failures))
+	for _, failure := range summary.Failures {
+		fmt.Fprintf(w.out, "- %s: %v\n", failure.Path, failure.Err)
+	}
+
+	fmt.Fprintln(w.out)
+}
+
+func (w *textWriter) printSymlinks(summary Summary) {
+	if len(summary.Symlinks) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w.out, "Found %d symbolic links\n", len(summary.Symlinks))
+	for _, link := range summary.Symlinks {
+		fmt.Fprintf(w.out, "- %s -> %s\n", link.Path, link.Target)
+	}
+
+	fmt.Fprintln(w.out)
+}
+
+func (w *textWriter) printVerificationIssues(summary Summary) {
+	if len(summary.VerificationIssues) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w.out, "Verification found %d files not matching their groups\n", len(summary.VerificationIssues))
+	for _, issue := range summary.VerificationIssues {
+		if issue.Reference != "" {
+			fmt.Fprintf(w.out, "- %s: %v (compared with %s)\n", issue.Path, issue.Err, issue.Reference)
+		} else {
+			fmt.Fprintf(w.out, "- %s: %v\n", issue.Path, issue.Err)
+		}
+	}
+
+	fmt.Fprintln(w.out)
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"path"
+
+	"github.com/pryazhnikov/gofileschecker/internal/glob"
+)
+
+// PathFilter decides which files and directories are scanned by their paths relative to the scanned root.
+// An excluded directory is skipped with all its content. If include patterns are set,
+// only the files matching them (or located in a matching directory) are scanned,
+// and the directories which cannot contain such files are skipped.
+type PathFilter struct {
+	include []*glob.Pattern
+	exclude []*glob.Pattern
+}
+
+func NewPathFilter(include []string, exclude []string) (*PathFilter, error) {
+	filter := &PathFilter{}
+	for _, pattern := range include {
+		compiled, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.include = append(filter.include, compiled)
+	}
+
+	for _, pattern := range exclude {
+		compiled, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.exclude = append(filter.exclude, compiled)
+	}
+
+	return filter, nil
+}
+
+// Skip checks if the slash-separated relative path should not be scanned
+func (f *PathFilter) Skip(relPath string, isDir bool) bool {
+	// The content of an excluded directory is excluded too
+	for name := relPath; name != "." && name != "/"; name = path.Dir(name) {
+		if f.excluded(name) {
+			return true
+		}
+	}
+
+	if len(f.include) == 0 {
+		return false
+	}
+
+	if isDir {
+		return !f.included(relPath) && !f.mayIncludeContent(relPath)
+	}
+
+	// The file is included with its directory too
+	for name := relPath; name != "." && name != "/"; name = path.Dir(name) {
+		if f.included(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *PathFilter) excluded(relPath string) bool {
+	for _, pattern := range f.exclude {
+		if pattern.Match(relPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *PathFilter) included(relPath string) bool {
+	for _, pattern := range f.include {
+		if pattern.Match(relPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *PathFilter) mayIncludeContent(dir string) bool {
+	for _, pattern := range f.include {
+		if pattern.MatchPrefix(dir) {
+			return true
+		}
+	}
+
+	return false
+}
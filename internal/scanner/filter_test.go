@@ -0,0 +1,88 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathFilter_Skip(t *testing.T) {
+	filter, err := NewPathFilter([]string{"*.jpg", "docs/**"}, []string{"node_modules", "photos/tmp"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{path: "photos/a.jpg", expected: false},
+		{path: "photos/a.txt", expected: true},
+		{path: "docs/guide/a.txt", expected: false},
+		{path: "app/node_modules", isDir: true, expected: true},
+		{path: "app/node_modules/lib/a.jpg", expected: true},
+		{path: "photos/tmp", isDir: true, expected: true},
+		{path: "photos/tmp/a.jpg", expected: true},
+		{path: "photos", isDir: true, expected: false},
+		{path: "docs", isDir: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, filter.Skip(tt.path, tt.isDir), "path %s", tt.path)
+	}
+}
+
+func TestPathFilter_IncludedDirectory(t *testing.T) {
+	filter, err := NewPathFilter([]string{"/photos/*.jpg"}, nil)
+	require.NoError(t, err)
+
+	assert.False(t, filter.Skip("photos", true))
+	assert.True(t, filter.Skip("music", true), "The directory cannot contain included files")
+	assert.True(t, filter.Skip("photos/2020", true), "The directory cannot contain included files")
+}
+
+func TestNewPathFilter_InvalidPattern(t *testing.T) {
+	_, err := NewPathFilter(nil, []string{"[a-"})
+	assert.Error(t, err)
+}
+
+func TestDirectoryScanner_Filter(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	for _, name := range []string{
+		"a.txt",
+		"b.log",
+		"src/c.txt",
+		"src/node_modules/d.txt",
+		"src/node_modules/lib/e.txt",
+		".git/objects/f.txt",
+	} {
+		path := filepath.Join(tempDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("test content"), 0644))
+	}
+
+	filter, err := NewPathFilter([]string{"*.txt"}, []string{"node_modules", ".git"})
+	require.NoError(t, err)
+	checker := &mockFileChecker{}
+	scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, Filter: filter})
+
+	// Act
+	err = scanner.Scan(tempDir)
+
+	// Assert
+	require.NoError(t, err)
+	added := slices.Clone(checker.added)
+	slices.Sort(added)
+	assert.Equal(t, []string{filepath.Join(tempDir, "a.txt"), filepath.Join(tempDir, "src/c.txt")}, added)
+
+	summary := scanner.Summary()
+	assert.Equal(t, 2, summary.Files())
+	assert.Equal(t, 3, summary.Skipped(), "b.log, node_modules and .git should be skipped")
+	assert.Equal(t, 2, summary.Directories(), "The root and src directories should be scanned")
+}
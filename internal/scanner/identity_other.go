@@ -0,0 +1,23 @@
+//go:build !unix
+
+package scanner
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// fileID identifies the file or the directory regardless of its path.
+// The device and inode are not available, so the path with resolved links is used.
+type fileID struct {
+	path string
+}
+
+func getFileID(path string, info fs.FileInfo) (fileID, error) {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fileID{}, err
+	}
+
+	return fileID{path: realPath}, nil
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// fileID identifies the file or the directory regardless of its path
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+func getFileID(path string, info fs.FileInfo) (fileID, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, fmt.Errorf("no device and inode info: %s", path)
+	}
+
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, nil
+}
@@ -0,0 +1,144 @@
+package scanner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pryazhnikov/gofileschecker/internal/glob"
+)
+
+// ToolIgnoreFile is the name of the ignore file with the rules for this tool only
+const ToolIgnoreFile = ".gofilecheckerignore"
+
+// DefaultIgnoreFiles are the ignore file names read in every directory, the rules of the later files win
+var DefaultIgnoreFiles = []string{".gitignore", ToolIgnoreFile}
+
+// ignoreRule is a pattern line of an ignore file
+type ignoreRule struct {
+	pattern *glob.Pattern
+	negate  bool // The matching path is not ignored
+	dirOnly bool // Only directories match the rule
+}
+
+// ignoreLevel holds the rules of the ignore files found in a directory
+type ignoreLevel struct {
+	dir   string // Slash-separated directory path relative to the scanned root, "." for the root
+	rules []ignoreRule
+}
+
+// ignoreStack holds the ignore rules of the directories on the current walk path, the root first.
+// The rules of a deeper directory override the rules of its parents, like git does.
+type ignoreStack struct {
+	names  []string
+	levels []ignoreLevel
+}
+
+func newIgnoreStack(names []string) *ignoreStack {
+	return &ignoreStack{names: names}
+}
+
+// load reads the ignore files of the directory, it should be called after its parents are loaded
+func (s *ignoreStack) load(dir string, relDir string) error {
+	var rules []ignoreRule
+	for _, name := range s.names {
+		fileRules, err := readIgnoreFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read ignore file: %w", err)
+		}
+
+		rules = append(rules, fileRules...)
+	}
+
+	if len(rules) > 0 {
+		s.levels = append(s.levels, ignoreLevel{dir: relDir, rules: rules})
+	}
+
+	return nil
+}
+
+// ignored checks if the slash-separated relative path is ignored, the last matching rule wins.
+// The paths should be checked in the walk order, the rules of the left directories are dropped.
+func (s *ignoreStack) ignored(relPath string, isDir bool) bool {
+	for len(s.levels) > 0 {
+		dir := s.levels[len(s.levels)-1].dir
+		if dir == "." || strings.HasPrefix(relPath, dir+"/") {
+			break
+		}
+
+		s.levels = s.levels[:len(s.levels)-1]
+	}
+
+	ignored := false
+	for _, level := range s.levels {
+		name := relPath
+		if level.dir != "." {
+			name = strings.TrimPrefix(relPath, level.dir+"/")
+		}
+
+		for _, rule := range level.rules {
+			if (isDir || !rule.dirOnly) && rule.pattern.Match(name) {
+				ignored = !rule.negate
+			}
+		}
+	}
+
+	return ignored
+}
+
+func readIgnoreFile(path string) ([]ignoreRule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parseIgnoreRules(file)
+}
+
+// parseIgnoreRules reads the rules in the .gitignore format
+func parseIgnoreRules(r io.Reader) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := trimTrailingSpaces(strings.TrimSuffix(lines.Text(), "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+
+		rule.dirOnly = strings.HasSuffix(line, "/")
+		pattern, err := glob.Compile(line)
+		if err != nil {
+			continue // Invalid patterns are skipped, like git does
+		}
+
+		rule.pattern = pattern
+		rules = append(rules, rule)
+	}
+
+	return rules, lines.Err()
+}
+
+// trimTrailingSpaces removes the trailing spaces which are not escaped with a backslash
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+
+	return line
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIgnoreRules(t *testing.T) {
+	// Arrange
+	content := "# Comment\n\nbuild/\n!keep.log\n*.log  \n\\#notes\r\n/root.txt\n[a-\n"
+
+	// Act
+	rules, err := parseIgnoreRules(strings.NewReader(content))
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, rules, 5, "Comments, empty lines and invalid patterns should be skipped")
+	assert.True(t, rules[0].dirOnly)
+	assert.True(t, rules[1].negate)
+	assert.Equal(t, "*.log", rules[2].pattern.String(), "Trailing spaces should be trimmed")
+	assert.True(t, rules[3].pattern.Match("#notes"), "Escaped hash should be matched literally")
+	assert.True(t, rules[4].pattern.Match("root.txt"))
+	assert.False(t, rules[4].pattern.Match("dir/root.txt"), "Pattern with slash should be anchored")
+}
+
+func TestIgnoreStack_Ignored(t *testing.T) {
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{path: "app.log", expected: true},
+		{path: "keep.log", expected: false},
+		{path: "build", isDir: true, expected: true},
+		{path: "build", expected: false},
+		{path: "src/debug.log", expected: true},
+		{path: "src/keep.log", expected: true},
+		{path: "src/main.go", expected: false},
+		{path: "src/gen", isDir: true, expected: true},
+		{path: "srcgen.txt", expected: false},
+		{path: "srcgen", isDir: true, expected: false},
+	}
+
+	// Arrange
+	stack := newIgnoreStack(nil)
+	rootRules, err := parseIgnoreRules(strings.NewReader("*.log\n!keep.log\nbuild/\n"))
+	require.NoError(t, err)
+	srcRules, err := parseIgnoreRules(strings.NewReader("keep.log\n/gen\n"))
+	require.NoError(t, err)
+	stack.levels = []ignoreLevel{{dir: ".", rules: rootRules}}
+
+	for _, tt := range tests {
+		if tt.path == "src/debug.log" {
+			stack.levels = append(stack.levels, ignoreLevel{dir: "src", rules: srcRules})
+		}
+
+		// Act & Assert
+		assert.Equal(t, tt.expected, stack.ignored(tt.path, tt.isDir), "path %s", tt.path)
+	}
+
+	assert.Len(t, stack.levels, 1, "The rules of the left directory should be dropped")
+}
+
+func TestDirectoryScanner_IgnoreFiles(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	files := map[string]string{
+		".gitignore":               "*.o\nbuild/\n",
+		"main.c":                   "test content",
+		"main.o":                   "test content",
+		"build/app":                "test content",
+		"lib/.gitignore":           "!keep.o\n",
+		"lib/keep.o":               "test content",
+		"lib/util.o":               "test content",
+		"lib/" + ToolIgnoreFile:    "*.bak\n",
+		"lib/util.bak":             "test content",
+		"other/util.bak":           "test content",
+		"other/build/generated.go": "test content",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	checker := &mockFileChecker{}
+	scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, IgnoreFiles: DefaultIgnoreFiles})
+
+	// Act
+	err := scanner.Scan(tempDir)
+
+	// Assert
+	require.NoError(t, err)
+	var added []string
+	for _, path := range checker.added {
+		relPath, err := filepath.Rel(tempDir, path)
+		require.NoError(t, err)
+		added = append(added, filepath.ToSlash(relPath))
+	}
+	slices.Sort(added)
+	expected := []string{".gitignore", "lib/" + ToolIgnoreFile, "lib/.gitignore", "lib/keep.o", "main.c", "other/util.bak"}
+	slices.Sort(expected)
+	assert.Equal(t, expected, added)
+	assert.Equal(t, 5, scanner.Summary().Skipped(), "main.o, build, lib/util.o, lib/util.bak and other/build should be skipped")
+}
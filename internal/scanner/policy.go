@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFileSkipped should be returned (or wrapped) by FileChecker when a file is intentionally not checked.
+// Such files are counted as skipped, not as errors.
+var ErrFileSkipped = errors.New("file skipped")
+
+// ErrorPolicy defines what the scanner does when a file cannot be processed
+type ErrorPolicy int
+
+const (
+	ErrorPolicyContinue     ErrorPolicy = iota // Record the error and keep scanning
+	ErrorPolicyAbortAfterN                     // Stop scanning when the errors limit is reached
+	ErrorPolicyAbortOnFirst                    // Stop scanning on the first error
+)
+
+var errorPolicyNames = map[ErrorPolicy]string{
+	ErrorPolicyContinue:     "continue",
+	ErrorPolicyAbortAfterN:  "abort-after",
+	ErrorPolicyAbortOnFirst: "abort",
+}
+
+func (p ErrorPolicy) String() string {
+	if name, ok := errorPolicyNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ErrorPolicy(%d)", int(p))
+}
+
+func ParseErrorPolicy(name string) (ErrorPolicy, error) {
+	for policy, policyName := range errorPolicyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown error policy: %q", name)
+}
+
+// FileError is a path which cannot be processed with the reason
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (fe FileError) Error() string {
+	return fmt.Sprintf("%s: %v", fe.Path, fe.Err)
+}
+
+func (fe FileError) Unwrap() error {
+	return fe.Err
+}
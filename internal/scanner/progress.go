@@ -0,0 +1,34 @@
+package scanner
+
+// ScanPhase is a stage of the scan reported in the progress
+type ScanPhase string
+
+const (
+	ScanPhaseWalk  ScanPhase = "walk"  // Directories are walked, files are registered
+	ScanPhaseCheck ScanPhase = "check" // Registered files are checked
+)
+
+// Progress is a snapshot of the scan state passed to the progress callback
+type Progress struct {
+	Phase      ScanPhase
+	Stats      ScanSummaryStats
+	Checked    int // Files checked in the current check round
+	Candidates int // Files to check in the current check round
+}
+
+// ProgressFunc is called periodically during the scan and at the end of every phase.
+// It can be called from multiple goroutines concurrently.
+type ProgressFunc func(progress Progress)
+
+func (ds *DirectoryScanner) reportProgress(phase ScanPhase, checked int, candidates int) {
+	if ds.onProgress == nil {
+		return
+	}
+
+	ds.onProgress(Progress{
+		Phase:      phase,
+		Stats:      ds.summary.Stats(),
+		Checked:    checked,
+		Candidates: candidates,
+	})
+}
@@ -1,38 +1,104 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
 const summaryPeriod = 100
 
+// The number of queued paths per worker. It limits the number of paths
+// which are not checked yet when a worker failure stops the check.
+const queueSizePerWorker = 16
+
+type Options struct {
+	Workers     int         // Number of files checked in parallel, CPU count is used if not positive
+	ErrorPolicy ErrorPolicy // What to do when a file cannot be processed
+	MaxErrors   int         // Errors limit for ErrorPolicyAbortAfterN
+	OnProgress  ProgressFunc
+	Filter      *PathFilter // Files and directories to skip, nothing is skipped if nil
+	IgnoreFiles []string    // Names of the .gitignore-style files applied to their directories, see DefaultIgnoreFiles
+	MinSize     int64       // Files smaller than the size in bytes are skipped
+	MaxSize     int64       // Files larger than the size in bytes are skipped, no limit if not positive
+	NewerThan   time.Time   // Files modified before the time are skipped, no limit if zero
+	OlderThan   time.Time   // Files modified after the time are skipped, no limit if zero
+	Symlinks    SymlinkPolicy
+}
+
 type DirectoryScanner struct {
 	logger       zerolog.Logger
 	checker      FileChecker
+	workers      int
+	errorPolicy  ErrorPolicy
+	maxErrors    int
+	onProgress   ProgressFunc
+	filter       *PathFilter
+	ignoreFiles  []string
+	minSize      int64
+	maxSize      int64
+	newerThan    time.Time
+	olderThan    time.Time
+	symlinks     SymlinkPolicy
+	pendingLinks []pendingLink
+	visitedDirs  map[fileID]bool // Walked directories, the roots may overlap through the links
+	visitedFiles map[fileID]bool // Registered files, the hard links are still registered by every path
 	scannedPaths map[string]bool
 	summary      *ScanSummaryCollector
 	mu           sync.RWMutex
 }
 
 type FileChecker interface {
+	// Add registers the file found during the directory walk, the file content should not be read here
+	Add(path string, size int64) error
+	// Candidates returns the registered files which should be passed to Check.
+	// It is called repeatedly until an empty list is returned, so each call should return only unchecked files.
+	Candidates() []string
+	// Check reads the file content and returns its hash
 	Check(path string) (string, error)
 }
 
-func NewDirectoryScanner(logger zerolog.Logger, checker FileChecker) *DirectoryScanner {
-	return &DirectoryScanner{
+func NewDirectoryScanner(logger zerolog.Logger, checker FileChecker, options Options) *DirectoryScanner {
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ds := &DirectoryScanner{
 		logger:       logger,
 		checker:      checker,
+		workers:      workers,
+		errorPolicy:  options.ErrorPolicy,
+		maxErrors:    options.MaxErrors,
+		onProgress:   options.OnProgress,
+		filter:       options.Filter,
+		ignoreFiles:  options.IgnoreFiles,
+		minSize:      options.MinSize,
+		maxSize:      options.MaxSize,
+		newerThan:    options.NewerThan,
+		olderThan:    options.OlderThan,
+		symlinks:     options.Symlinks,
+		visitedDirs:  make(map[fileID]bool),
+		visitedFiles: make(map[fileID]bool),
 		scannedPaths: make(map[string]bool),
 		summary:      &ScanSummaryCollector{},
 		mu:           sync.RWMutex{},
 	}
+
+	return ds
 }
 
+// Scan walks the directory and registers all found files in the checker.
+// File contents are not read here, see CheckFiles. With SymlinkFollow policy the links
+// are resolved by CheckFiles, so the links to the files of all scanned roots are detected.
 func (ds *DirectoryScanner) Scan(rootPath string) error {
 	// Get absolute path to handle different path formats pointing to same directory
 	absPath, err := filepath.Abs(rootPath)
@@ -44,37 +110,272 @@ func (ds *DirectoryScanner) Scan(rootPath string) error {
 		return fmt.Errorf("scanned paths field is not initialized")
 	}
 
-	if ds.isPathScanned(absPath) {
+	// The root passed explicitly is resolved like find -H does, the symlinks policy applies to the found links only
+	realPath, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	if ds.isPathScanned(absPath) || ds.isPathScanned(realPath) {
 		ds.logger.Info().Msgf("Directory already scanned, skipping: %s", absPath)
 		return nil
 	}
 
 	ds.logger.Info().Msgf("Starting directory scan: %s", absPath)
-	err = filepath.WalkDir(absPath, func(path string, d fs.DirEntry, err error) error {
+	err = ds.walk(absPath, absPath, realPath)
+	ds.reportProgress(ScanPhaseWalk, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	ds.markPathAsScanned(absPath)
+	ds.markPathAsScanned(realPath)
+	return nil
+}
+
+// walk registers the files of the directory located in the scanned root.
+// The directory can be a link, then its target is walked and the found paths are reported inside the link.
+func (ds *DirectoryScanner) walk(rootPath string, dir string, realDir string) error {
+	linked := dir != realDir
+	ignores := newIgnoreStack(ds.ignoreFiles)
+	return filepath.WalkDir(realDir, func(path string, d fs.DirEntry, err error) error {
+		if linked {
+			relPath, relErr := filepath.Rel(realDir, path)
+			if relErr != nil {
+				return fmt.Errorf("failed to get relative path: %w", relErr)
+			}
+
+			path = filepath.Join(dir, relPath)
+		}
+
 		if err != nil {
-			return err
+			if path == rootPath {
+				return err // Nothing to scan without the root directory
+			}
+
+			ds.logger.Warn().
+				Str("path", path).
+				Msgf("Cannot read path: %v", err)
+			return ds.handleError(path, err)
+		}
+
+		relPath, err := filepath.Rel(rootPath, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+
+		// The walked directory itself is already checked by the filters
+		relPath = filepath.ToSlash(relPath)
+		if path != dir && ds.isFiltered(relPath, path, d, ignores) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			return ds.processSymlink(rootPath, path)
 		}
 
 		if d.IsDir() {
+			if ds.isVisitedDirectory(path, d) {
+				return fs.SkipDir
+			}
+
+			if err := ignores.load(path, relPath); err != nil {
+				ds.logger.Warn().
+					Str("path", path).
+					Msgf("Cannot load ignore rules: %v", err)
+				if err := ds.handleError(path, err); err != nil {
+					return err
+				}
+			}
+
 			return ds.processDirectory(path)
 		}
 
-		// todo: to implement processing retry later
-		return ds.processFile(path)
+		// The root file is skipped if another root already registered it
+		if err := ds.processFile(path, d, linked || path == dir); err != nil {
+			return err
+		}
+
+		if ds.summary.Files()%summaryPeriod == 0 {
+			ds.reportProgress(ScanPhaseWalk, 0, 0)
+		}
+
+		return nil
 	})
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to scan directory: %w", err)
+// followLinks registers the files and directories the links found with SymlinkFollow policy point to.
+// The links to the walked files and directories are skipped, it prevents both the loops
+// and reporting the links as duplicates of their targets.
+func (ds *DirectoryScanner) followLinks() error {
+	if len(ds.pendingLinks) == 0 {
+		return nil
 	}
 
-	ds.markPathAsScanned(absPath)
+	for len(ds.pendingLinks) > 0 {
+		link := ds.pendingLinks[0]
+		ds.pendingLinks = ds.pendingLinks[1:]
+
+		info, err := os.Stat(link.path)
+		if err != nil {
+			ds.summary.AddFile() // The broken link is counted like an unreadable file
+			ds.logger.Warn().
+				Str("path", link.path).
+				Msgf("Cannot resolve symbolic link: %v", err)
+			if err := ds.handleError(link.path, err); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !info.IsDir() {
+			ds.summary.AddFile()
+			if err := ds.registerFile(link.path, info, true); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		target, err := filepath.EvalSymlinks(link.path)
+		if err != nil {
+			ds.logger.Warn().
+				Str("path", link.path).
+				Msgf("Cannot resolve symbolic link: %v", err)
+			if err := ds.handleError(link.path, err); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := ds.walk(link.rootPath, link.path, target); err != nil {
+			return err
+		}
+	}
+
+	ds.reportProgress(ScanPhaseWalk, 0, 0)
 	return nil
 }
 
+// CheckFiles checks the contents of the files registered by Scan calls.
+// It should be called after all directories are scanned, the checker asks only for files which can have duplicates.
+func (ds *DirectoryScanner) CheckFiles() error {
+	if err := ds.followLinks(); err != nil {
+		return fmt.Errorf("failed to scan linked directory: %w", err)
+	}
+
+	for {
+		candidates := ds.checker.Candidates()
+		if len(candidates) == 0 {
+			return nil
+		}
+
+		ds.logger.Info().Msgf("Files to check: %d (workers: %d)", len(candidates), ds.workers)
+		if err := ds.checkCandidates(candidates); err != nil {
+			return fmt.Errorf("failed to check files: %w", err)
+		}
+	}
+}
+
+func (ds *DirectoryScanner) checkCandidates(candidates []string) error {
+	// The candidates are fed into the bounded queue, the workers check them in parallel.
+	// An error which should stop the scan according to the error policy stops both the feeder and the workers.
+	queue := make(chan string, ds.workers*queueSizePerWorker)
+	stopped := make(chan struct{})
+	var stopOnce sync.Once
+	var checkErr error
+	stop := func(err error) {
+		stopOnce.Do(func() {
+			checkErr = err
+			close(stopped)
+		})
+	}
+
+	var checkedCount atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < ds.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range queue {
+				if isStopped(stopped) {
+					continue // Draining the queue without checking
+				}
+
+				// todo: to implement processing retry later
+				if err := ds.checkFile(path); err != nil {
+					stop(err)
+				}
+
+				if count := checkedCount.Add(1); count%summaryPeriod == 0 {
+					ds.logger.Info().Msgf(
+						"%d of %d files checked, errors: %d...",
+						count,
+						len(candidates),
+						ds.summary.Errors(),
+					)
+					ds.reportProgress(ScanPhaseCheck, int(count), len(candidates))
+				}
+			}
+		}()
+	}
+
+feeding:
+	for _, path := range candidates {
+		select {
+		case queue <- path:
+		case <-stopped:
+			break feeding
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	ds.reportProgress(ScanPhaseCheck, int(checkedCount.Load()), len(candidates))
+	return checkErr
+}
+
+func isStopped(stopped <-chan struct{}) bool {
+	select {
+	case <-stopped:
+		return true
+	default:
+		return false
+	}
+}
+
 func (ds *DirectoryScanner) Summary() ScanSummaryStats {
 	return ds.summary.Stats()
 }
 
+// Failures returns all paths which cannot be processed, in the order of their processing
+func (ds *DirectoryScanner) Failures() []FileError {
+	return ds.summary.Failures()
+}
+
+// handleError records the error and returns non-nil value if the scan should be stopped
+func (ds *DirectoryScanner) handleError(path string, err error) error {
+	errorsCount := ds.summary.AddError(path, err)
+
+	switch ds.errorPolicy {
+	case ErrorPolicyAbortOnFirst:
+		return FileError{Path: path, Err: err}
+	case ErrorPolicyAbortAfterN:
+		if errorsCount >= ds.maxErrors {
+			return fmt.Errorf("errors limit reached (%d), last one: %w", errorsCount, FileError{Path: path, Err: err})
+		}
+	}
+
+	return nil
+}
+
 func (ds *DirectoryScanner) isPathScanned(absPath string) bool {
 	ds.mu.RLock()
 	defer ds.mu.RUnlock()
@@ -87,6 +388,116 @@ func (ds *DirectoryScanner) markPathAsScanned(absPath string) {
 	ds.scannedPaths[absPath] = true
 }
 
+// isFiltered checks if the path is skipped by the filter or the ignore files and counts it as skipped
+func (ds *DirectoryScanner) isFiltered(relPath string, path string, d fs.DirEntry, ignores *ignoreStack) bool {
+	switch {
+	case ds.filter != nil && ds.filter.Skip(relPath, d.IsDir()):
+		ds.logger.Debug().
+			Str("path", path).
+			Msg("Path skipped by the filter")
+	case ignores.ignored(relPath, d.IsDir()):
+		ds.logger.Debug().
+			Str("path", path).
+			Msg("Path skipped by the ignore files")
+	default:
+		return false
+	}
+
+	ds.summary.AddSkipped()
+	return true
+}
+
+func (ds *DirectoryScanner) isSizeAllowed(size int64) bool {
+	return size >= ds.minSize && (ds.maxSize <= 0 || size <= ds.maxSize)
+}
+
+func (ds *DirectoryScanner) isModTimeAllowed(modTime time.Time) bool {
+	if !ds.newerThan.IsZero() && modTime.Before(ds.newerThan) {
+		return false
+	}
+
+	return ds.olderThan.IsZero() || !modTime.After(ds.olderThan)
+}
+
+// processSymlink handles the link according to the symlink policy
+func (ds *DirectoryScanner) processSymlink(rootPath string, path string) error {
+	switch ds.symlinks {
+	case SymlinkFollow:
+		ds.pendingLinks = append(ds.pendingLinks, pendingLink{rootPath: rootPath, path: path})
+	case SymlinkReport:
+		target, err := os.Readlink(path)
+		if err != nil {
+			ds.logger.Warn().
+				Str("path", path).
+				Msgf("Cannot read symbolic link: %v", err)
+			return ds.handleError(path, err)
+		}
+
+		ds.logger.Info().
+			Str("path", path).
+			Str("target", target).
+			Msg("Symbolic link found")
+		ds.summary.AddSymlink(path, target)
+	default:
+		ds.logger.Debug().
+			Str("path", path).
+			Msg("Symbolic link skipped")
+		ds.summary.AddSkipped()
+	}
+
+	return nil
+}
+
+// isVisitedDirectory checks if the directory was already walked by another path and counts it as skipped.
+// The directories cannot be hard linked, so it happens only if the roots overlap or a link is followed.
+func (ds *DirectoryScanner) isVisitedDirectory(path string, d fs.DirEntry) bool {
+	info, err := d.Info()
+	if err != nil {
+		return false // The walk reports the directory error
+	}
+
+	id, err := getFileID(path, info)
+	if err != nil {
+		return false
+	}
+
+	if ds.visitedDirs[id] {
+		ds.logger.Debug().
+			Str("path", path).
+			Msg("Directory skipped: already walked by another path")
+		ds.summary.AddSkipped()
+		return true
+	}
+
+	ds.visitedDirs[id] = true
+	return false
+}
+
+// isVisitedFile checks if the linked file was already registered and counts it as skipped.
+// The files found by the walk are not linked, so the hard links are still reported.
+func (ds *DirectoryScanner) isVisitedFile(path string, info fs.FileInfo, linked bool) bool {
+	id, err := getFileID(path, info)
+	if err != nil {
+		return false
+	}
+
+	if linked && ds.visitedFiles[id] {
+		ds.logger.Debug().
+			Str("path", path).
+			Msg("File skipped: the link target is already registered")
+		ds.summary.AddSkipped()
+		return true
+	}
+
+	ds.visitedFiles[id] = true
+	return false
+}
+
+// Symlinks returns the links found with SymlinkReport policy
+func (ds *DirectoryScanner) Symlinks() []Symlink {
+	return ds.summary.Symlinks()
+}
+
 func (ds *DirectoryScanner) processDirectory(path string) error {
 	ds.logger.Debug().
 		Str("path", path).
@@ -95,19 +506,85 @@ func (ds *DirectoryScanner) processDirectory(path string) error {
 	return nil
 }
 
-func (ds *DirectoryScanner) processFile(path string) error {
+func (ds *DirectoryScanner) processFile(path string, d fs.DirEntry, linked bool) error {
 	ds.logger.Debug().
 		Str("path", path).
-		Msg("File found, the check is expected")
+		Msg("File found, registering it for the check")
 
 	ds.summary.AddFile()
+
+	info, err := d.Info()
+	if err != nil {
+		ds.logger.Warn().
+			Str("path", path).
+			Msgf("Cannot get file info: %v", err)
+		return ds.handleError(path, err)
+	}
+
+	return ds.registerFile(path, info, linked)
+}
+
+// registerFile adds the file to the checker unless it is skipped by its info.
+// The linked file is skipped if it was registered by another path.
+func (ds *DirectoryScanner) registerFile(path string, info fs.FileInfo, linked bool) error {
+	// Reading devices, sockets or pipes can block forever
+	if !info.Mode().IsRegular() {
+		ds.logger.Debug().
+			Str("path", path).
+			Str("mode", info.Mode().String()).
+			Msg("File skipped: not a regular file")
+		ds.summary.AddSkipped()
+		return nil
+	}
+
+	if !ds.isSizeAllowed(info.Size()) {
+		ds.logger.Debug().
+			Str("path", path).
+			Int64("size", info.Size()).
+			Msg("File skipped: size is out of range")
+		ds.summary.AddSkipped()
+		return nil
+	}
+
+	if !ds.isModTimeAllowed(info.ModTime()) {
+		ds.logger.Debug().
+			Str("path", path).
+			Time("mtime", info.ModTime()).
+			Msg("File skipped: modification time is out of range")
+		ds.summary.AddSkipped()
+		return nil
+	}
+
+	if ds.isVisitedFile(path, info, linked) {
+		return nil
+	}
+
+	err := ds.checker.Add(path, info.Size())
+	if errors.Is(err, ErrFileSkipped) {
+		ds.logger.Debug().
+			Str("path", path).
+			Msgf("File skipped: %v", err)
+		ds.summary.AddSkipped()
+		return nil
+	}
+
+	if err != nil {
+		ds.logger.Warn().
+			Str("path", path).
+			Msgf("Cannot register file: %v", err)
+		return ds.handleError(path, err)
+	}
+
+	return nil
+}
+
+func (ds *DirectoryScanner) checkFile(path string) error {
 	checkRes, err := ds.checker.Check(path)
 	if err != nil {
 		ds.logger.Warn().
 			Str("path", path).
 			Msgf("Cannot check file: %v", err)
-		ds.summary.AddError()
-		return err
+		return ds.handleError(path, err)
 	}
 
 	ds.logger.Debug().
@@ -115,13 +592,5 @@ func (ds *DirectoryScanner) processFile(path string) error {
 		Str("hash", checkRes).
 		Msg("File was checked")
 
-	if ds.summary.Files()%summaryPeriod == 0 {
-		ds.logger.Info().Msgf(
-			"%d files processed, errors: %d...",
-			ds.summary.Files(),
-			ds.summary.Errors(),
-		)
-	}
-
 	return nil
 }
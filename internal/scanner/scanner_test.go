@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,25 +16,67 @@ import (
 
 type mockFileChecker struct {
 	mu            sync.Mutex
+	added         []string
+	checked       map[string]bool
 	checkCount    int
 	checkDuration time.Duration
+	running       atomic.Int32 // Checks running at the moment
+	maxRunning    atomic.Int32 // Peak number of the checks running in parallel
 	shouldError   bool
+	shouldSkip    bool
 }
 
-func (m *mockFileChecker) Check(path string) (string, error) {
+func (m *mockFileChecker) Add(path string, size int64) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	
-	m.checkCount++
-	
+
+	if m.shouldSkip {
+		return fmt.Errorf("%w: mock skip for %s", ErrFileSkipped, path)
+	}
+
+	m.added = append(m.added, path)
+	return nil
+}
+
+func (m *mockFileChecker) Candidates() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []string
+	for _, path := range m.added {
+		if !m.checked[path] {
+			result = append(result, path)
+		}
+	}
+
+	return result
+}
+
+func (m *mockFileChecker) Check(path string) (string, error) {
+	// The check duration is spent outside the lock, so the parallel checks overlap
+	running := m.running.Add(1)
+	defer m.running.Add(-1)
+	for peak := m.maxRunning.Load(); running > peak && !m.maxRunning.CompareAndSwap(peak, running); {
+		peak = m.maxRunning.Load()
+	}
+
 	if m.checkDuration > 0 {
 		time.Sleep(m.checkDuration)
 	}
-	
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checkCount++
+	if m.checked == nil {
+		m.checked = make(map[string]bool)
+	}
+	m.checked[path] = true
+
 	if m.shouldError {
 		return "", fmt.Errorf("mock error for %s", path)
 	}
-	
+
 	return fmt.Sprintf("hash-%s", filepath.Base(path)), nil
 }
 
@@ -43,12 +86,27 @@ func (m *mockFileChecker) getCheckCount() int {
 	return m.checkCount
 }
 
+func (m *mockFileChecker) getAddedCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.added)
+}
+
+// scanAndCheck runs both scan phases like the application does
+func scanAndCheck(scanner *DirectoryScanner, path string) error {
+	if err := scanner.Scan(path); err != nil {
+		return err
+	}
+
+	return scanner.CheckFiles()
+}
+
 func TestNewDirectoryScanner(t *testing.T) {
 	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
 	checker := &mockFileChecker{}
 
 	t.Run("creates scanner successfully", func(t *testing.T) {
-		scanner := NewDirectoryScanner(logger, checker)
+		scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1})
 		assert.NotNil(t, scanner)
 		assert.NotNil(t, scanner.summary)
 		assert.NotNil(t, scanner.scannedPaths)
@@ -68,9 +126,9 @@ func TestDirectoryScanner_BasicFunctionality(t *testing.T) {
 
 	t.Run("processes all files successfully", func(t *testing.T) {
 		checker := &mockFileChecker{}
-		scanner := NewDirectoryScanner(logger, checker)
+		scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1})
 
-		err := scanner.Scan(tempDir)
+		err := scanAndCheck(scanner, tempDir)
 
 		require.NoError(t, err)
 		assert.Equal(t, len(testFiles), checker.getCheckCount())
@@ -82,17 +140,222 @@ func TestDirectoryScanner_BasicFunctionality(t *testing.T) {
 
 	t.Run("handles file check errors", func(t *testing.T) {
 		checker := &mockFileChecker{shouldError: true}
-		scanner := NewDirectoryScanner(logger, checker)
+		scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, ErrorPolicy: ErrorPolicyAbortOnFirst})
 
-		err := scanner.Scan(tempDir)
+		err := scanAndCheck(scanner, tempDir)
 		require.Error(t, err) // Scan should error on first file check failure
 
-		// Only one file should be processed before error stops the scan
+		// All files are found, but only one should be checked before error stops the scan
 		assert.Equal(t, 1, checker.getCheckCount())
 		summary := scanner.Summary()
-		assert.Equal(t, 1, summary.Files())
+		assert.Equal(t, len(testFiles), summary.Files())
 		assert.Equal(t, 1, summary.Errors())
+		assert.Len(t, scanner.Failures(), 1)
+	})
+
+	t.Run("continues after file check errors", func(t *testing.T) {
+		checker := &mockFileChecker{shouldError: true}
+		scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, ErrorPolicy: ErrorPolicyContinue})
+
+		err := scanAndCheck(scanner, tempDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, len(testFiles), checker.getCheckCount())
+		summary := scanner.Summary()
+		assert.Equal(t, len(testFiles), summary.Files())
+		assert.Equal(t, len(testFiles), summary.Errors())
+
+		failures := scanner.Failures()
+		require.Len(t, failures, len(testFiles))
+		for i, filename := range testFiles {
+			assert.Equal(t, filepath.Join(tempDir, filename), failures[i].Path)
+			assert.Error(t, failures[i].Err)
+		}
+	})
+
+	t.Run("aborts after errors limit", func(t *testing.T) {
+		checker := &mockFileChecker{shouldError: true}
+		scanner := NewDirectoryScanner(logger, checker, Options{
+			Workers:     1,
+			ErrorPolicy: ErrorPolicyAbortAfterN,
+			MaxErrors:   3,
+		})
+
+		err := scanAndCheck(scanner, tempDir)
+		require.Error(t, err)
+
+		assert.Equal(t, 3, checker.getCheckCount())
+		assert.Equal(t, 3, scanner.Summary().Errors())
+	})
+
+	t.Run("counts skipped files", func(t *testing.T) {
+		checker := &mockFileChecker{shouldSkip: true}
+		scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, ErrorPolicy: ErrorPolicyAbortOnFirst})
+
+		err := scanAndCheck(scanner, tempDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, checker.getCheckCount())
+		summary := scanner.Summary()
+		assert.Equal(t, len(testFiles), summary.Files())
+		assert.Equal(t, len(testFiles), summary.Skipped())
+		assert.Equal(t, 0, summary.Errors())
+	})
+
+	t.Run("scan does not check files", func(t *testing.T) {
+		checker := &mockFileChecker{}
+		scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1})
+
+		err := scanner.Scan(tempDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, len(testFiles), checker.getAddedCount())
+		assert.Equal(t, 0, checker.getCheckCount())
+	})
+}
+
+func TestDirectoryScanner_SizeRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		expected []string
+	}{
+		{name: "no limits", options: Options{}, expected: []string{"empty.txt", "large.txt", "small.txt"}},
+		{name: "empty files skipped", options: Options{MinSize: 1}, expected: []string{"large.txt", "small.txt"}},
+		{name: "min size", options: Options{MinSize: 10}, expected: []string{"large.txt"}},
+		{name: "max size", options: Options{MaxSize: 10}, expected: []string{"empty.txt", "small.txt"}},
+		{name: "both limits", options: Options{MinSize: 1, MaxSize: 99}, expected: []string{"small.txt"}},
+	}
+
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "empty.txt"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "small.txt"), []byte("small"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "large.txt"), make([]byte, 100), 0644))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			checker := &mockFileChecker{}
+			tt.options.Workers = 1
+			scanner := NewDirectoryScanner(logger, checker, tt.options)
+
+			// Act
+			err := scanner.Scan(tempDir)
+
+			// Assert
+			require.NoError(t, err)
+			var added []string
+			for _, path := range checker.added {
+				added = append(added, filepath.Base(path))
+			}
+			assert.ElementsMatch(t, tt.expected, added)
+			assert.Equal(t, 3-len(tt.expected), scanner.Summary().Skipped())
+		})
+	}
+}
+
+func TestDirectoryScanner_ModTimeRange(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		options  Options
+		expected []string
+	}{
+		{name: "no limits", options: Options{}, expected: []string{"new.txt", "old.txt", "recent.txt"}},
+		{name: "newer than", options: Options{NewerThan: now.AddDate(0, 0, -7)}, expected: []string{"new.txt", "recent.txt"}},
+		{name: "older than", options: Options{OlderThan: now.AddDate(0, 0, -7)}, expected: []string{"old.txt"}},
+		{
+			name:     "both limits",
+			options:  Options{NewerThan: now.AddDate(0, 0, -7), OlderThan: now.Add(-time.Hour)},
+			expected: []string{"recent.txt"},
+		},
+	}
+
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	modTimes := map[string]time.Time{
+		"old.txt":    now.AddDate(-1, 0, 0),
+		"recent.txt": now.AddDate(0, 0, -1),
+		"new.txt":    now,
+	}
+	for name, modTime := range modTimes {
+		path := filepath.Join(tempDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("test content"), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			checker := &mockFileChecker{}
+			tt.options.Workers = 1
+			scanner := NewDirectoryScanner(logger, checker, tt.options)
+
+			// Act
+			err := scanner.Scan(tempDir)
+
+			// Assert
+			require.NoError(t, err)
+			var added []string
+			for _, path := range checker.added {
+				added = append(added, filepath.Base(path))
+			}
+			assert.ElementsMatch(t, tt.expected, added)
+			assert.Equal(t, 3-len(tt.expected), scanner.Summary().Skipped(), "Filtered files should be counted as skipped")
+		})
+	}
+}
+
+func TestDirectoryScanner_Progress(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+
+	numFiles := summaryPeriod + 1
+	for i := 0; i < numFiles; i++ {
+		filename := fmt.Sprintf("file%d.txt", i)
+		err := os.WriteFile(filepath.Join(tempDir, filename), []byte("test content"), 0644)
+		require.NoError(t, err)
+	}
+
+	var mu sync.Mutex
+	var progresses []Progress
+	checker := &mockFileChecker{}
+	scanner := NewDirectoryScanner(logger, checker, Options{
+		Workers: 2,
+		OnProgress: func(progress Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progresses = append(progresses, progress)
+		},
 	})
+
+	err := scanAndCheck(scanner, tempDir)
+	require.NoError(t, err)
+
+	// Periodic and final progress for both phases are expected
+	var phases []ScanPhase
+	for _, progress := range progresses {
+		phases = append(phases, progress.Phase)
+	}
+	assert.Equal(t, []ScanPhase{ScanPhaseWalk, ScanPhaseWalk, ScanPhaseCheck, ScanPhaseCheck}, phases)
+
+	assert.Equal(t, summaryPeriod, progresses[0].Stats.Files())
+	assert.Equal(t, numFiles, progresses[1].Stats.Files())
+	assert.Equal(t, summaryPeriod, progresses[2].Checked)
+	assert.Equal(t, numFiles, progresses[3].Checked)
+	assert.Equal(t, numFiles, progresses[3].Candidates)
+}
+
+func TestParseErrorPolicy(t *testing.T) {
+	for _, policy := range []ErrorPolicy{ErrorPolicyContinue, ErrorPolicyAbortAfterN, ErrorPolicyAbortOnFirst} {
+		parsed, err := ParseErrorPolicy(policy.String())
+		require.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+
+	_, err := ParseErrorPolicy("unknown")
+	assert.Error(t, err)
 }
 
 func TestDirectoryScanner_ScanSummary(t *testing.T) {
@@ -116,9 +379,9 @@ func TestDirectoryScanner_ScanSummary(t *testing.T) {
 	require.NoError(t, err)
 
 	checker := &mockFileChecker{}
-	scanner := NewDirectoryScanner(logger, checker)
+	scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1})
 
-	err = scanner.Scan(tempDir)
+	err = scanAndCheck(scanner, tempDir)
 	require.NoError(t, err)
 
 	// Verify scan summary
@@ -127,4 +390,30 @@ func TestDirectoryScanner_ScanSummary(t *testing.T) {
 	assert.Equal(t, numFiles+1, summary.Files())
 	assert.Equal(t, 2, summary.Directories()) // tempDir + subDir
 	assert.Equal(t, 0, summary.Errors())
-}
\ No newline at end of file
+}
+
+func TestDirectoryScanner_MultipleWorkers(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+
+	numFiles := 50
+	for i := 0; i < numFiles; i++ {
+		filename := fmt.Sprintf("file%d.txt", i)
+		err := os.WriteFile(filepath.Join(tempDir, filename), []byte("test content"), 0644)
+		require.NoError(t, err)
+	}
+
+	checker := &mockFileChecker{checkDuration: time.Millisecond}
+	scanner := NewDirectoryScanner(logger, checker, Options{Workers: 4})
+	assert.Equal(t, 4, scanner.workers)
+
+	err := scanAndCheck(scanner, tempDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, numFiles, checker.getCheckCount())
+	assert.Greater(t, checker.maxRunning.Load(), int32(1), "The files should be checked in parallel")
+	summary := scanner.Summary()
+	assert.Equal(t, numFiles, summary.Files())
+	assert.Equal(t, 1, summary.Directories())
+	assert.Equal(t, 0, summary.Errors())
+}
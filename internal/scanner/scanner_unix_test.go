@@ -0,0 +1,38 @@
+//go:build unix
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectoryScanner_SkipsNonRegularFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+
+	err := os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("test content"), 0644)
+	require.NoError(t, err)
+	err = os.Symlink(filepath.Join(tempDir, "missing.txt"), filepath.Join(tempDir, "broken-link"))
+	require.NoError(t, err)
+	err = syscall.Mkfifo(filepath.Join(tempDir, "pipe"), 0644)
+	require.NoError(t, err)
+
+	checker := &mockFileChecker{}
+	scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, Symlinks: SymlinkFollow})
+
+	err = scanAndCheck(scanner, tempDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, checker.getAddedCount())
+	summary := scanner.Summary()
+	assert.Equal(t, 3, summary.Files())
+	assert.Equal(t, 1, summary.Skipped(), "The pipe should be skipped")
+	assert.Equal(t, 1, summary.Errors(), "The broken link should be reported")
+}
@@ -26,8 +26,10 @@ func (s ScanSummaryStats) Skipped() int {
 }
 
 type ScanSummaryCollector struct {
-	data ScanSummaryStats
-	mu   sync.RWMutex
+	data     ScanSummaryStats
+	failures []FileError
+	symlinks []Symlink
+	mu       sync.RWMutex
 }
 
 func (s *ScanSummaryCollector) Files() int {
@@ -66,10 +68,13 @@ func (s *ScanSummaryCollector) AddDirectory() {
 	s.data.directories++
 }
 
-func (s *ScanSummaryCollector) AddError() {
+// AddError records the failed path, increments the errors counter and returns its new value
+func (s *ScanSummaryCollector) AddError(path string, err error) int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.data.errors++
+	s.failures = append(s.failures, FileError{Path: path, Err: err})
+	return s.data.errors
 }
 
 func (s *ScanSummaryCollector) AddSkipped() {
@@ -78,8 +83,33 @@ func (s *ScanSummaryCollector) AddSkipped() {
 	s.data.skipped++
 }
 
+// AddSymlink records the symbolic link which is not followed
+func (s *ScanSummaryCollector) AddSymlink(path string, target string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.symlinks = append(s.symlinks, Symlink{Path: path, Target: target})
+}
+
 func (s *ScanSummaryCollector) Stats() ScanSummaryStats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return s.data
 }
+
+func (s *ScanSummaryCollector) Failures() []FileError {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]FileError, len(s.failures))
+	copy(result, s.failures)
+	return result
+}
+
+func (s *ScanSummaryCollector) Symlinks() []Symlink {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Symlink, len(s.symlinks))
+	copy(result, s.symlinks)
+	return result
+}
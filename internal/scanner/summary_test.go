@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,14 +50,23 @@ func TestScanSummary_AddError(t *testing.T) {
 	// Arrange
 	summary := &ScanSummaryCollector{}
 
+	checkErr := errors.New("test error")
+
 	// Act
-	summary.AddError()
+	errorsCount := summary.AddError("/path/to/file.txt", checkErr)
 
 	// Assert
+	assert.Equal(t, 1, errorsCount, "AddError() should return the new errors count")
 	assert.Equal(t, 0, summary.Files(), "Files should remain 0")
 	assert.Equal(t, 0, summary.Directories(), "Directories should remain 0")
 	assert.Equal(t, 1, summary.Errors(), "Errors should be 1 after AddError()")
 	assert.Equal(t, 0, summary.Skipped(), "Skipped should remain 0")
+	assert.Equal(
+		t,
+		[]FileError{{Path: "/path/to/file.txt", Err: checkErr}},
+		summary.Failures(),
+		"Failed path should be recorded",
+	)
 }
 
 func TestScanSummary_AddSkipped(t *testing.T) {
@@ -83,7 +93,7 @@ func TestScanSummary_MultipleIncrements(t *testing.T) {
 	summary.AddFile()
 	summary.AddDirectory()
 	summary.AddDirectory()
-	summary.AddError()
+	summary.AddError("file.txt", errors.New("test error"))
 	summary.AddSkipped()
 	summary.AddSkipped()
 	summary.AddSkipped()
@@ -94,4 +104,4 @@ func TestScanSummary_MultipleIncrements(t *testing.T) {
 	assert.Equal(t, 2, summary.Directories(), "Directories should be 2 after 2 AddDirectory() calls")
 	assert.Equal(t, 1, summary.Errors(), "Errors should be 1 after 1 AddError() call")
 	assert.Equal(t, 4, summary.Skipped(), "Skipped should be 4 after 4 AddSkipped() calls")
-}
\ No newline at end of file
+}
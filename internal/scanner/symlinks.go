@@ -0,0 +1,48 @@
+package scanner
+
+import "fmt"
+
+// SymlinkPolicy defines what the scanner does with symbolic links
+type SymlinkPolicy int
+
+const (
+	SymlinkSkip   SymlinkPolicy = iota // Skip the links, they are counted as skipped
+	SymlinkFollow                      // Scan the linked files and directories, the loops are detected
+	SymlinkReport                      // Do not follow the links, list them in the summary
+)
+
+var symlinkPolicyNames = map[SymlinkPolicy]string{
+	SymlinkSkip:   "skip",
+	SymlinkFollow: "follow",
+	SymlinkReport: "report",
+}
+
+func (p SymlinkPolicy) String() string {
+	if name, ok := symlinkPolicyNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("SymlinkPolicy(%d)", int(p))
+}
+
+func ParseSymlinkPolicy(name string) (SymlinkPolicy, error) {
+	for policy, policyName := range symlinkPolicyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown symlink policy: %q", name)
+}
+
+// Symlink is a symbolic link found with SymlinkReport policy
+type Symlink struct {
+	Path   string
+	Target string // The link content as is, it can be relative to the link directory
+}
+
+// pendingLink is a symbolic link found with SymlinkFollow policy, it is resolved once all roots are walked
+type pendingLink struct {
+	rootPath string
+	path     string
+}
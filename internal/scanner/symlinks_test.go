@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSymlinkPolicy(t *testing.T) {
+	for policy, name := range symlinkPolicyNames {
+		parsed, err := ParseSymlinkPolicy(name)
+		require.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+		assert.Equal(t, name, policy.String())
+	}
+
+	_, err := ParseSymlinkPolicy("ignore")
+	assert.Error(t, err)
+}
+
+// writeSymlinkTree creates the scanned root with the links to its own content, to its parent and to the outside directory
+func writeSymlinkTree(t *testing.T) (string, string) {
+	baseDir := t.TempDir()
+	rootDir := filepath.Join(baseDir, "root")
+	outsideDir := filepath.Join(baseDir, "outside")
+	require.NoError(t, os.MkdirAll(filepath.Join(rootDir, "real"), 0755))
+	require.NoError(t, os.MkdirAll(outsideDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "real", "a.txt"), []byte("test content"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outsideDir, "b.txt"), []byte("test content"), 0644))
+
+	links := map[string]string{
+		"link-file":    filepath.Join("real", "a.txt"),
+		"link-dir":     "real",
+		"real/loop":    "..",
+		"outside-file": filepath.Join(outsideDir, "b.txt"),
+		"outside-link": outsideDir,
+	}
+	for link, target := range links {
+		require.NoError(t, os.Symlink(target, filepath.Join(rootDir, link)))
+	}
+
+	return rootDir, outsideDir
+}
+
+func TestDirectoryScanner_Symlinks(t *testing.T) {
+	tests := []struct {
+		name             string
+		policy           SymlinkPolicy
+		expectedAdded    []string
+		expectedSkipped  int
+		expectedSymlinks int
+	}{
+		{
+			name:            "skip",
+			policy:          SymlinkSkip,
+			expectedAdded:   []string{"real/a.txt"},
+			expectedSkipped: 5,
+		},
+		{
+			name:             "report",
+			policy:           SymlinkReport,
+			expectedAdded:    []string{"real/a.txt"},
+			expectedSymlinks: 5,
+		},
+		{
+			// The links to the walked files and directories (including the loop) are skipped,
+			// the outside file is registered once by the first link
+			name:            "follow",
+			policy:          SymlinkFollow,
+			expectedAdded:   []string{"real/a.txt", "outside-file"},
+			expectedSkipped: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			rootDir, _ := writeSymlinkTree(t)
+			logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+			checker := &mockFileChecker{}
+			scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, Symlinks: tt.policy})
+
+			// Act
+			err := scanAndCheck(scanner, rootDir)
+
+			// Assert
+			require.NoError(t, err)
+			var added []string
+			for _, path := range checker.added {
+				relPath, err := filepath.Rel(rootDir, path)
+				require.NoError(t, err)
+				added = append(added, filepath.ToSlash(relPath))
+			}
+			assert.ElementsMatch(t, tt.expectedAdded, added)
+			assert.Equal(t, tt.expectedSkipped, scanner.Summary().Skipped())
+			assert.Equal(t, 0, scanner.Summary().Errors())
+			assert.Len(t, scanner.Symlinks(), tt.expectedSymlinks)
+		})
+	}
+}
+
+func TestDirectoryScanner_FollowLinkToLaterRoot(t *testing.T) {
+	// Arrange
+	rootDir, outsideDir := writeSymlinkTree(t)
+	logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+	checker := &mockFileChecker{}
+	scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, Symlinks: SymlinkFollow})
+
+	// Act
+	require.NoError(t, scanner.Scan(rootDir))
+	require.NoError(t, scanner.Scan(outsideDir))
+	err := scanner.CheckFiles()
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(rootDir, "real", "a.txt"),
+		filepath.Join(outsideDir, "b.txt"),
+	}, checker.added, "The links should not be registered besides their targets")
+	assert.Equal(t, 5, scanner.Summary().Skipped())
+}
+
+func TestDirectoryScanner_SymlinkedRoot(t *testing.T) {
+	// The "link" root points to the "real" directory with the "a" subdirectory
+	tests := []struct {
+		name          string
+		roots         []string
+		expectedAdded []string
+	}{
+		{
+			name:          "link and its target",
+			roots:         []string{"link", "real"},
+			expectedAdded: []string{"link/a/x.txt", "link/y.txt"},
+		},
+		{
+			name:          "subdirectory and the link containing it",
+			roots:         []string{"real/a", "link"},
+			expectedAdded: []string{"real/a/x.txt", "link/y.txt"},
+		},
+		{
+			name:          "link and the subdirectory of its target",
+			roots:         []string{"link", "real/a"},
+			expectedAdded: []string{"link/a/x.txt", "link/y.txt"},
+		},
+		{
+			name:          "link and the file of its target",
+			roots:         []string{"link", "real/y.txt"},
+			expectedAdded: []string{"link/a/x.txt", "link/y.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "real", "a"), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "real", "a", "x.txt"), []byte("x"), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "real", "y.txt"), []byte("y"), 0644))
+			require.NoError(t, os.Symlink(filepath.Join(dir, "real"), filepath.Join(dir, "link")))
+
+			logger := zerolog.New(os.Stdout).Level(zerolog.Disabled)
+			checker := &mockFileChecker{}
+			scanner := NewDirectoryScanner(logger, checker, Options{Workers: 1, Symlinks: SymlinkSkip})
+
+			// Act
+			for _, root := range tt.roots {
+				require.NoError(t, scanner.Scan(filepath.Join(dir, root)))
+			}
+			err := scanner.CheckFiles()
+
+			// Assert
+			require.NoError(t, err)
+			var added []string
+			for _, path := range checker.added {
+				relPath, err := filepath.Rel(dir, path)
+				require.NoError(t, err)
+				added = append(added, filepath.ToSlash(relPath))
+			}
+			assert.ElementsMatch(t, tt.expectedAdded, added, "Every file should be registered once")
+			assert.Equal(t, 0, scanner.Summary().Errors())
+			assert.Empty(t, scanner.Symlinks())
+		})
+	}
+}
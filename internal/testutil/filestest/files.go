@@ -0,0 +1,52 @@
+// Package filestest contains the test helpers creating files and passing them through a file checker.
+// It does not depend on the checkers package, so the checkers tests use it too.
+package filestest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Checker is the part of the file checker passing the files through the check phases
+type Checker interface {
+	Add(path string, size int64) error
+	Candidates() []string
+	Check(path string) (string, error)
+}
+
+// WriteFiles creates the files with the given contents in the directory and returns their paths by file names.
+// The names may contain subdirectories, they are created too.
+func WriteFiles(t testing.TB, dir string, contents map[string]string) map[string]string {
+	t.Helper()
+
+	paths := make(map[string]string, len(contents))
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		paths[name] = path
+	}
+
+	return paths
+}
+
+// CheckFiles registers the files in the checker and passes them through all the check phases
+func CheckFiles(t testing.TB, checker Checker, paths map[string]string) {
+	t.Helper()
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NoError(t, checker.Add(path, info.Size()))
+	}
+
+	for candidates := checker.Candidates(); len(candidates) > 0; candidates = checker.Candidates() {
+		for _, path := range candidates {
+			_, err := checker.Check(path)
+			require.NoError(t, err)
+		}
+	}
+}
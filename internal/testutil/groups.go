@@ -0,0 +1,22 @@
+// Package testutil contains the test helpers shared by several packages
+package testutil
+
+import (
+	"testing"
+
+	"github.com/pryazhnikov/gofileschecker/internal/checkers"
+	"github.com/pryazhnikov/gofileschecker/internal/testutil/filestest"
+	"github.com/stretchr/testify/require"
+)
+
+// NewGroups creates the files in a temporary directory and returns the directory with their duplicated groups
+func NewGroups(t testing.TB, contents map[string]string) (string, []*checkers.FilesCheckGroup) {
+	t.Helper()
+
+	dir := t.TempDir()
+	fc, err := checkers.NewFileChecker(checkers.Options{})
+	require.NoError(t, err)
+	filestest.CheckFiles(t, fc, filestest.WriteFiles(t, dir, contents))
+
+	return dir, fc.GetDuplicatedFileGroups()
+}
@@ -0,0 +1,153 @@
+// Package xxh64 implements the 64-bit xxHash algorithm with zero seed.
+// It is much faster than cryptographic hashes and is good enough to find duplicated files
+// when the results are verified byte-by-byte.
+package xxh64
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	Size      = 8  // The size of the checksum in bytes
+	BlockSize = 32 // The block size of the hash algorithm in bytes
+)
+
+// The primes are variables to allow overflowing arithmetic with them
+var (
+	prime1 uint64 = 11400714785074694791
+	prime2 uint64 = 14029467366897019727
+	prime3 uint64 = 1609587929392839161
+	prime4 uint64 = 9650029242287828579
+	prime5 uint64 = 2870177450012600261
+)
+
+type digest struct {
+	v1, v2, v3, v4 uint64
+	total          uint64
+	mem            [BlockSize]byte
+	n              int // Number of bytes buffered in mem
+}
+
+// Ensure digest implements hash.Hash64 interface
+var _ hash.Hash64 = (*digest)(nil)
+
+func New() hash.Hash64 {
+	d := &digest{}
+	d.Reset()
+	return d
+}
+
+// Sum64 returns the checksum of the data
+func Sum64(data []byte) uint64 {
+	d := &digest{}
+	d.Reset()
+	_, _ = d.Write(data)
+	return d.Sum64()
+}
+
+func (d *digest) Reset() {
+	d.v1 = prime1 + prime2
+	d.v2 = prime2
+	d.v3 = 0
+	d.v4 = -prime1
+	d.total = 0
+	d.n = 0
+}
+
+func (d *digest) Size() int {
+	return Size
+}
+
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+func (d *digest) Write(data []byte) (int, error) {
+	written := len(data)
+	d.total += uint64(written)
+
+	// Filling the buffer first
+	if d.n > 0 {
+		copied := copy(d.mem[d.n:], data)
+		d.n += copied
+		data = data[copied:]
+		if d.n < BlockSize {
+			return written, nil
+		}
+
+		d.processBlock(d.mem[:])
+		d.n = 0
+	}
+
+	for ; len(data) >= BlockSize; data = data[BlockSize:] {
+		d.processBlock(data[:BlockSize])
+	}
+
+	d.n = copy(d.mem[:], data)
+	return written, nil
+}
+
+func (d *digest) processBlock(block []byte) {
+	d.v1 = round(d.v1, binary.LittleEndian.Uint64(block[0:8]))
+	d.v2 = round(d.v2, binary.LittleEndian.Uint64(block[8:16]))
+	d.v3 = round(d.v3, binary.LittleEndian.Uint64(block[16:24]))
+	d.v4 = round(d.v4, binary.LittleEndian.Uint64(block[24:32]))
+}
+
+func (d *digest) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, d.Sum64())
+}
+
+func (d *digest) Sum64() uint64 {
+	var h uint64
+	if d.total >= BlockSize {
+		h = bits.RotateLeft64(d.v1, 1) + bits.RotateLeft64(d.v2, 7) +
+			bits.RotateLeft64(d.v3, 12) + bits.RotateLeft64(d.v4, 18)
+		h = mergeRound(h, d.v1)
+		h = mergeRound(h, d.v2)
+		h = mergeRound(h, d.v3)
+		h = mergeRound(h, d.v4)
+	} else {
+		h = d.v3 + prime5 // v3 holds the seed until the first block is processed
+	}
+
+	h += d.total
+
+	tail := d.mem[:d.n]
+	for ; len(tail) >= 8; tail = tail[8:] {
+		h ^= round(0, binary.LittleEndian.Uint64(tail[:8]))
+		h = bits.RotateLeft64(h, 27)*prime1 + prime4
+	}
+
+	if len(tail) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(tail[:4])) * prime1
+		h = bits.RotateLeft64(h, 23)*prime2 + prime3
+		tail = tail[4:]
+	}
+
+	for _, b := range tail {
+		h ^= uint64(b) * prime5
+		h = bits.RotateLeft64(h, 11) * prime1
+	}
+
+	h ^= h >> 33
+	h *= prime2
+	h ^= h >> 29
+	h *= prime3
+	h ^= h >> 32
+
+	return h
+}
+
+func round(acc, input uint64) uint64 {
+	acc += input * prime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * prime1
+}
+
+func mergeRound(acc, val uint64) uint64 {
+	acc ^= round(0, val)
+	return acc*prime1 + prime4
+}
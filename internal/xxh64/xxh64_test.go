@@ -0,0 +1,44 @@
+package xxh64
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum64(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint64
+	}{
+		{input: "", expected: 0xef46db3751d8e999},
+		{input: "a", expected: 0xd24ec4f1a98c6e5b},
+		{input: "abc", expected: 0x44bc2cf5ad770999},
+		{input: "Nobody inspects the spammish repetition", expected: 0xfbcea83c8a378bf1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Sum64([]byte(tt.input)))
+		})
+	}
+}
+
+// The result should not depend on how the data is split into Write calls
+func TestDigest_StreamingWrites(t *testing.T) {
+	data := []byte(strings.Repeat("0123456789abcdef", 20) + "tail")
+	expected := Sum64(data)
+
+	for _, chunkSize := range []int{1, 3, 7, 31, 32, 33, 100} {
+		d := New()
+		for start := 0; start < len(data); start += chunkSize {
+			end := min(start+chunkSize, len(data))
+			_, err := d.Write(data[start:end])
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, expected, d.Sum64(), "wrong hash for chunk size %d", chunkSize)
+		assert.Len(t, d.Sum(nil), Size)
+	}
+}